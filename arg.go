@@ -0,0 +1,75 @@
+package parsex
+
+import (
+	"fmt"
+	"strings"
+)
+
+// [Arg] describes a positional argument of the program, see [runtimeType.SetArgs(...)]
+type Arg struct {
+	Name string
+	// Will be displayed in the "Arguments:" section of the --help menu
+	Desc string
+	// Whether the argument can be omitted
+	Optional bool
+	// Whether the argument accepts multiple values. Must be the last argument
+	Variadic bool
+	// Minimum and maximum number of values of a variadic argument, 0 means no limit.
+	// Optional arguments can always be omitted, so they can't have a minimum
+	Min int
+	Max int
+}
+
+// Parses the argument the way [runtimeType.SetPosArgs(...)] describes it
+func parseArg(spec string) Arg {
+	variadic := strings.HasSuffix(spec, "...")
+	optional := strings.Contains(spec, "?")
+	name := strings.TrimSuffix(spec, "...")
+	name = strings.ReplaceAll(name, "?", "")
+	return Arg{Name: name, Optional: optional, Variadic: variadic}
+}
+
+// Returns the argument as displayed in the --help menu, e.g. `<files?...>`
+func (arg Arg) String() string {
+	name := arg.Name
+	if arg.Optional {
+		name += "?"
+	}
+	if arg.Variadic {
+		name += "..."
+	}
+	return "<" + name + ">"
+}
+
+// Returns the number of values that the argument requires
+func (arg Arg) minCount() int {
+	switch {
+	case arg.Optional:
+		return 0
+	case arg.Variadic:
+		return max(arg.Min, 1)
+	}
+	return 1
+}
+
+// Returns the number of values that the argument accepts, 0 means no limit
+func (arg Arg) maxCount() int {
+	if !arg.Variadic {
+		return 1
+	}
+	return arg.Max
+}
+
+func (arg Arg) notes() string {
+	var notes []string
+	if arg.Variadic && arg.Min != 0 {
+		notes = append(notes, fmt.Sprintf("min: %d", arg.Min))
+	}
+	if arg.Variadic && arg.Max != 0 {
+		notes = append(notes, fmt.Sprintf("max: %d", arg.Max))
+	}
+	if len(notes) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(notes, ", ") + ")"
+}
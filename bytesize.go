@@ -0,0 +1,119 @@
+package parsex
+
+import (
+	"fmt"
+	"math"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// [ByteSize] is an option type for sizes in bytes.
+//
+// Accepts an optional SI (`KB`, `MB`, ...) or IEC (`KiB`, `MiB`, ...) suffix,
+// single letters (`K`, `M`, ...) are treated as IEC units. Fractions are allowed
+// as long as the result is a whole number of bytes, e.g. `1.5G`.
+type ByteSize uint64
+
+const (
+	Byte     ByteSize = 1
+	Kibibyte          = Byte << 10
+	Mebibyte          = Kibibyte << 10
+	Gibibyte          = Mebibyte << 10
+	Tebibyte          = Gibibyte << 10
+	Pebibyte          = Tebibyte << 10
+	Exbibyte          = Pebibyte << 10
+)
+
+var byteUnits = map[string]ByteSize{
+	"":    Byte,
+	"b":   Byte,
+	"k":   Kibibyte,
+	"kib": Kibibyte,
+	"kb":  1e3,
+	"m":   Mebibyte,
+	"mib": Mebibyte,
+	"mb":  1e6,
+	"g":   Gibibyte,
+	"gib": Gibibyte,
+	"gb":  1e9,
+	"t":   Tebibyte,
+	"tib": Tebibyte,
+	"tb":  1e12,
+	"p":   Pebibyte,
+	"pib": Pebibyte,
+	"pb":  1e15,
+	"e":   Exbibyte,
+	"eib": Exbibyte,
+	"eb":  1e18,
+}
+
+// Used by [ByteSize.String()] from the largest to the smallest
+var byteSuffixes = []struct {
+	suffix string
+	size   ByteSize
+}{
+	{"EiB", Exbibyte},
+	{"PiB", Pebibyte},
+	{"TiB", Tebibyte},
+	{"GiB", Gibibyte},
+	{"MiB", Mebibyte},
+	{"KiB", Kibibyte},
+}
+
+func (size *ByteSize) Set(value string) error {
+	value = strings.TrimSpace(value)
+	end := strings.IndexFunc(value, func(char rune) bool {
+		return (char < '0' || char > '9') && char != '.'
+	})
+	if end == -1 {
+		end = len(value)
+	}
+	number, suffix := value[:end], strings.TrimSpace(value[end:])
+
+	unit, exists := byteUnits[strings.ToLower(suffix)]
+	if !exists {
+		return fmt.Errorf("unknown size unit %q", suffix)
+	}
+
+	if !strings.Contains(number, ".") {
+		num, err := strconv.ParseUint(number, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid size %q", value)
+		}
+		hi, lo := bits.Mul64(num, uint64(unit))
+		if hi != 0 {
+			return fmt.Errorf("size %q is out of range", value)
+		}
+		*size = ByteSize(lo)
+		return nil
+	}
+
+	num, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return fmt.Errorf("invalid size %q", value)
+	}
+	result := num * float64(unit)
+	if result >= math.MaxUint64 {
+		return fmt.Errorf("size %q is out of range", value)
+	}
+	if result != math.Trunc(result) {
+		return fmt.Errorf("size %q is not a whole number of bytes", value)
+	}
+	*size = ByteSize(result)
+	return nil
+}
+
+// Returns the size using the largest IEC unit that represents it exactly, e.g. `64MiB`
+func (size ByteSize) String() string {
+	for _, unit := range byteSuffixes {
+		if size >= unit.size && size%unit.size == 0 {
+			return fmt.Sprintf("%d%s", size/unit.size, unit.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", uint64(size))
+}
+
+func (size ByteSize) Type() string {
+	return "size"
+}
@@ -25,12 +25,36 @@ const (
 	ErrKindSettingOption
 	ErrKindUnknownCluster
 	ErrKindMistypedCluster
+
+	// New kinds are appended to keep the values of the existing ones stable
+
+	ErrKindInvalidChoice
+	ErrKindInvalidPath
+	ErrKindInvalidTag
+	ErrKindConstraint
+	ErrKindMissingOption
+	ErrKindConflictingOptions
+	ErrKindMissingDependency
+	ErrKindOneOfGroup
+	ErrKindValidation
+	ErrKindInvalidArg
+	ErrKindTooManyArgs
+	ErrKindVariadicNotLast
+	ErrKindRequiredAfterOptional
+	ErrKindInvalidArgRange
+	ErrKindInvalidEnv
 )
 
 type ErrProgramData struct {
 	ErrKind ErrKind
 	Name    string
 	Type    reflect.Type
+	// Set with [ErrKindInvalidTag]
+	Field string
+	// Set with [ErrKindInvalidTag] and [ErrKindInvalidArgRange]
+	Err error
+	// Set with [ErrKindVariadicNotLast], [ErrKindRequiredAfterOptional] and [ErrKindInvalidArgRange]
+	Arg string
 }
 
 func (err ErrProgramData) Error() string {
@@ -47,6 +71,32 @@ func (err ErrProgramData) Error() string {
 			err.Name,
 			err.Type,
 		)
+	case ErrKindInvalidTag:
+		return fmt.Sprintf(
+			"%s: invalid tag of Program.Data field %q: %s",
+			err.Name,
+			err.Field,
+			err.Err.Error(),
+		)
+	case ErrKindVariadicNotLast:
+		return fmt.Sprintf(
+			"%s: only the last positional argument can be variadic. Got <%s...> instead",
+			err.Name,
+			err.Arg,
+		)
+	case ErrKindRequiredAfterOptional:
+		return fmt.Sprintf(
+			"%s: required positional argument <%s> must come before the optional ones",
+			err.Name,
+			err.Arg,
+		)
+	case ErrKindInvalidArgRange:
+		return fmt.Sprintf(
+			"%s: invalid range of positional argument <%s>: %s",
+			err.Name,
+			err.Arg,
+			err.Err.Error(),
+		)
 	}
 
 	return errUnknownType
@@ -69,13 +119,37 @@ func (err ErrExecution) Error() string {
 	return errUnknownType
 }
 
+type ErrValidation struct {
+	ErrKind ErrKind
+	Name    string
+	Err     error
+}
+
+func (err ErrValidation) Error() string {
+	switch err.ErrKind {
+	case ErrKindValidation:
+		return fmt.Sprintf(
+			"%s: %s. Refer to --help for usage information",
+			err.Name,
+			err.Err.Error(),
+		)
+	}
+
+	return errUnknownType
+}
+
 type ErrInput struct {
 	ErrKind     ErrKind
 	Name        string
 	RequiredLen int
+	// Negative if there's no limit
+	MaxLen      int
 	ProvidedLen int
 	ExecArgs    []string
 	ArgPrinter  func(io.Writer)
+	// Set with [ErrKindInvalidArg]
+	Arg string
+	Err error
 }
 
 func (err ErrInput) Error() string {
@@ -95,6 +169,28 @@ func (err ErrInput) Error() string {
 			err.ExecArgs,
 		)
 		return builder.String()
+	case ErrKindTooManyArgs:
+		var builder strings.Builder
+		fmt.Fprintf(&builder,
+			"%s: at most %d positional argument(s) is/are accepted: `",
+			err.Name,
+			err.MaxLen,
+		)
+		err.ArgPrinter(&builder)
+		fmt.Fprintf(
+			&builder,
+			"`, but provided %d `%s`",
+			err.ProvidedLen,
+			err.ExecArgs,
+		)
+		return builder.String()
+	case ErrKindInvalidArg:
+		return fmt.Sprintf(
+			"%s: invalid value of argument <%s>: %s",
+			err.Name,
+			err.Arg,
+			err.Err.Error(),
+		)
 	}
 
 	return errUnknownType
@@ -105,6 +201,13 @@ type ErrOption struct {
 	Name    string
 	Option  string
 	Err     error
+	// Values allowed for the option, set with [ErrKindInvalidChoice]
+	Choices []string
+	// Other options involved, set with [ErrKindMissingOption], [ErrKindConflictingOptions],
+	// [ErrKindMissingDependency] and [ErrKindOneOfGroup]
+	Related []string
+	// Environment variable of the option, set with [ErrKindInvalidEnv]
+	Env string
 }
 
 func (err ErrOption) Error() string {
@@ -141,6 +244,47 @@ func (err ErrOption) Error() string {
 			err.Option,
 			err.Err.Error(),
 		)
+	case ErrKindMissingOption:
+		return fmt.Sprintf(
+			"%s: missing required option(s) %s. Refer to --help for usage information",
+			err.Name,
+			strings.Join(err.Related, ", "),
+		)
+	case ErrKindConflictingOptions:
+		return fmt.Sprintf(
+			"%s: option %q cannot be used together with %s. Refer to --help for usage information",
+			err.Name,
+			err.Option,
+			strings.Join(err.Related, ", "),
+		)
+	case ErrKindMissingDependency:
+		return fmt.Sprintf(
+			"%s: option %q requires %s. Refer to --help for usage information",
+			err.Name,
+			err.Option,
+			strings.Join(err.Related, ", "),
+		)
+	case ErrKindOneOfGroup:
+		return fmt.Sprintf(
+			"%s: exactly one of %s must be provided. Refer to --help for usage information",
+			err.Name,
+			strings.Join(err.Related, ", "),
+		)
+	case ErrKindInvalidEnv:
+		return fmt.Sprintf(
+			"%s: invalid value of environment variable %s for option %q: %s",
+			err.Name,
+			err.Env,
+			err.Option,
+			err.Err.Error(),
+		)
+	case ErrKindInvalidChoice, ErrKindInvalidPath, ErrKindConstraint:
+		return fmt.Sprintf(
+			"%s: invalid value of option %q: %s",
+			err.Name,
+			err.Option,
+			err.Err.Error(),
+		)
 	}
 
 	return errUnknownType
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"cmp"
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"unicode/utf8"
+)
+
+// [ConstraintError] is returned when the value violates one of the constraints of [Option]
+type ConstraintError struct {
+	Value      string
+	Constraint string
+	Limit      string
+}
+
+func (err ConstraintError) Error() string {
+	switch err.Constraint {
+	case "min":
+		return fmt.Sprintf("%q is less than min: %s", err.Value, err.Limit)
+	case "max":
+		return fmt.Sprintf("%q is greater than max: %s", err.Value, err.Limit)
+	case "minlen":
+		return fmt.Sprintf("%q is shorter than minlen: %s", err.Value, err.Limit)
+	case "maxlen":
+		return fmt.Sprintf("%q is longer than maxlen: %s", err.Value, err.Limit)
+	case "pattern":
+		return fmt.Sprintf("%q does not match pattern: %s", err.Value, err.Limit)
+	}
+	return fmt.Sprintf("%q violates %s: %s", err.Value, err.Constraint, err.Limit)
+}
+
+// Parses the constraints of the option. Must be called before the option is set
+func (option *Option) Compile() error {
+	var err error
+	if option.MinLen != "" {
+		if option.minLen, err = strconv.Atoi(option.MinLen); err != nil {
+			return fmt.Errorf("minlen: %w", err)
+		}
+	}
+	if option.MaxLen != "" {
+		if option.maxLen, err = strconv.Atoi(option.MaxLen); err != nil {
+			return fmt.Errorf("maxlen: %w", err)
+		}
+	}
+	if option.Pattern != "" {
+		if option.pattern, err = regexp.Compile("^(?:" + option.Pattern + ")$"); err != nil {
+			return fmt.Errorf("pattern: %w", err)
+		}
+	}
+
+	typ := option.scalarType()
+	if option.Min != "" {
+		if option.min, err = option.compileLimit(typ, option.Min); err != nil {
+			return fmt.Errorf("min: %w", err)
+		}
+	}
+	if option.Max != "" {
+		if option.max, err = option.compileLimit(typ, option.Max); err != nil {
+			return fmt.Errorf("max: %w", err)
+		}
+	}
+	return nil
+}
+
+func (option *Option) compileLimit(typ reflect.Type, limit string) (reflect.Value, error) {
+	value, err := option.parse(typ, limit)
+	if err != nil {
+		return value, err
+	}
+	if _, ok := compare(value, value); !ok {
+		return value, fmt.Errorf("type %q can't be compared", typ)
+	}
+	return value, nil
+}
+
+// Returns the type of a single value of the option, without slices, maps or pointers
+func (option *Option) scalarType() reflect.Type {
+	typ := option.Type
+	if option.IsRepeatable() {
+		typ = typ.Elem()
+	}
+	for typ.Kind() == reflect.Pointer && !isScalar(typ) {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// Validates the raw string of a single value (or an element) against
+// `minlen`, `maxlen` and `pattern` constraints
+func (option *Option) checkText(value string) error {
+	length := utf8.RuneCountInString(value)
+	if option.MinLen != "" && length < option.minLen {
+		return ConstraintError{Value: value, Constraint: "minlen", Limit: option.MinLen}
+	}
+	if option.MaxLen != "" && length > option.maxLen {
+		return ConstraintError{Value: value, Constraint: "maxlen", Limit: option.MaxLen}
+	}
+	if option.pattern != nil && !option.pattern.MatchString(value) {
+		return ConstraintError{Value: value, Constraint: "pattern", Limit: option.Pattern}
+	}
+	return nil
+}
+
+// Validates the parsed value (or an element) against `min` and `max` constraints
+func (option *Option) checkRange(raw string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer && !isScalar(value.Type()) {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if option.min.IsValid() {
+		if result, ok := compare(value, option.min); ok && result < 0 {
+			return ConstraintError{Value: raw, Constraint: "min", Limit: option.Min}
+		}
+	}
+	if option.max.IsValid() {
+		if result, ok := compare(value, option.max); ok && result > 0 {
+			return ConstraintError{Value: raw, Constraint: "max", Limit: option.Max}
+		}
+	}
+	return nil
+}
+
+// Compares two numeric values of the same type
+func compare(a, b reflect.Value) (int, bool) {
+	switch a.Type() {
+	case bigIntType:
+		return a.Interface().(*big.Int).Cmp(b.Interface().(*big.Int)), true
+	case bigFloatType:
+		return a.Interface().(*big.Float).Cmp(b.Interface().(*big.Float)), true
+	}
+
+	switch a.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return cmp.Compare(a.Int(), b.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return cmp.Compare(a.Uint(), b.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return cmp.Compare(a.Float(), b.Float()), true
+	}
+	return 0, false
+}
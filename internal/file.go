@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+)
+
+var (
+	fileType   = reflect.TypeOf((*os.File)(nil))
+	readerType = reflect.TypeOf((*io.Reader)(nil)).Elem()
+	writerType = reflect.TypeOf((*io.Writer)(nil)).Elem()
+)
+
+// Flags of [os.OpenFile] for every supported `mode` tag
+var fileModes = map[string]int{
+	"r":  os.O_RDONLY,
+	"w":  os.O_WRONLY | os.O_CREATE | os.O_TRUNC,
+	"a":  os.O_WRONLY | os.O_CREATE | os.O_APPEND,
+	"rw": os.O_RDWR | os.O_CREATE,
+}
+
+// [PathError] is returned when the path doesn't pass [Option.Exists] or [Option.Creatable] checks
+type PathError struct {
+	Path   string
+	Reason string
+}
+
+func (err PathError) Error() string {
+	return fmt.Sprintf("path %q %s", err.Path, err.Reason)
+}
+
+// Whether the option opens a file (or uses stdin/stdout if "-" is provided)
+func (option *Option) IsFile() bool {
+	return isFile(option.Type)
+}
+
+func isFile(typ reflect.Type) bool {
+	switch typ {
+	case fileType, readerType, writerType:
+		return true
+	}
+	if typ.Kind() == reflect.Slice {
+		return isFile(typ.Elem())
+	}
+	return false
+}
+
+// Records the path (or the paths, split by [Option.Sep]) of a file option after validating it.
+// Values from a lower priority source are discarded first
+func (option *Option) setPaths(source Source, value string) error {
+	parts := []string{value}
+	if option.Type.Kind() == reflect.Slice {
+		parts = option.split(source, value)
+	} else if source == SourceDefault && value == "" {
+		parts = nil
+	}
+	for _, part := range parts {
+		if err := option.check(part); err != nil {
+			return err
+		}
+	}
+
+	if option.source < source || option.Type.Kind() != reflect.Slice {
+		option.paths = nil
+		option.Ref.SetZero()
+	}
+	option.paths = append(option.paths, parts...)
+	option.source = source
+	return nil
+}
+
+// Opens the files of the recorded paths and sets the option.
+//
+// The files are closed by [Option.Close()]
+func (option *Option) Open() error {
+	if option.Ref == nil || !option.IsFile() {
+		return nil
+	}
+	typ := option.Type
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+
+	values := make([]reflect.Value, 0, len(option.paths))
+	for _, path := range option.paths {
+		file, err := option.open(typ, path)
+		if err != nil {
+			return err
+		}
+		value := reflect.New(typ).Elem()
+		value.Set(reflect.ValueOf(file))
+		values = append(values, value)
+	}
+
+	switch {
+	case option.Type.Kind() == reflect.Slice:
+		option.Ref.Set(reflect.Append(reflect.MakeSlice(option.Type, 0, len(values)), values...))
+	case len(values) != 0:
+		option.Ref.Set(values[len(values)-1])
+	}
+	return nil
+}
+
+// Opens the file using [Option.Mode]. Readers default to "r", writers to "w".
+//
+// The file is closed by [Option.Close()]
+func (option *Option) open(typ reflect.Type, path string) (*os.File, error) {
+	mode := option.Mode
+	if mode == "" {
+		mode = "r"
+		if typ == writerType {
+			mode = "w"
+		}
+	}
+	flags, exists := fileModes[mode]
+	if !exists {
+		return nil, fmt.Errorf("unknown file mode %q", mode)
+	}
+
+	if path == "-" {
+		if mode == "r" {
+			return os.Stdin, nil
+		}
+		return os.Stdout, nil
+	}
+
+	file, err := os.OpenFile(path, flags, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	option.files = append(option.files, file)
+	return file, nil
+}
+
+// Closes all files opened by the option
+func (option *Option) Close() error {
+	var firstErr error
+	for _, file := range option.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	option.files = nil
+	return firstErr
+}
+
+// Validates the path according to [Option.Exists] and [Option.Creatable]
+func (option *Option) checkPath(path string) error {
+	if (option.Exists == "" && !option.Creatable) || path == "-" {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	switch option.Exists {
+	case "file":
+		if err != nil {
+			return PathError{Path: path, Reason: "does not exist"}
+		}
+		if info.IsDir() {
+			return PathError{Path: path, Reason: "is a directory"}
+		}
+	case "dir":
+		if err != nil {
+			return PathError{Path: path, Reason: "does not exist"}
+		}
+		if !info.IsDir() {
+			return PathError{Path: path, Reason: "is not a directory"}
+		}
+	}
+
+	if option.Creatable {
+		if err == nil {
+			if info.IsDir() {
+				return PathError{Path: path, Reason: "is a directory"}
+			}
+			return nil
+		}
+		parent, err := os.Stat(filepath.Dir(path))
+		if err != nil || !parent.IsDir() {
+			return PathError{Path: path, Reason: "cannot be created: parent directory does not exist"}
+		}
+	}
+
+	return nil
+}
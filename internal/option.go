@@ -3,93 +3,502 @@ package internal
 import (
 	"errors"
 	"fmt"
+	"os"
 	"reflect"
-	"strconv"
+	"regexp"
+	"slices"
+	"strings"
 )
 
-var flagType = reflect.TypeOf(true)
+var (
+	flagType        = reflect.TypeOf(true)
+	boolPointerType = reflect.PointerTo(flagType)
+)
 
 var HelpOption = Option{
 	Name: "help",
-	Alt:  "",
 	Desc: "Print this help message",
 	Type: flagType,
 	Ref:  nil,
 }
 
+var HelpAllOption = Option{
+	Name: "help-all",
+	Desc: "Print this help message including hidden and deprecated options",
+	Type: flagType,
+	Ref:  nil,
+}
+
 var VersionOption = Option{
 	Name: "version",
-	Alt:  "",
 	Desc: "Print the program version",
 	Type: flagType,
 	Ref:  nil,
 }
 
+// [Source] describes where the current value of an [Option] came from
+type Source int
+
+const (
+	SourceNone Source = iota
+	SourceDefault
+	SourceEnv
+	SourceInput
+)
+
 type Option struct {
-	Name    string
-	Alt     string
+	Name string
+	// Single letter alternatives, e.g. `-o`
+	Alts []string
+	// Long alternatives, e.g. names of the option before it was renamed
+	Aliases []string
 	Desc    string
 	Default string
+	// Name of the environment variable used if the option isn't provided
+	Env string
+	// Splits a single value of a slice/map option into multiple elements.
+	// Empty string disables splitting
+	Sep string
+	// Layout of [time.Time] options, RFC 3339 if empty
+	Layout string
+	// Values allowed for the option (or each of its elements), any if empty
+	Choices []string
+	// Whether every occurrence of the (integer) option increments its value
+	Count bool
+	// Whether the value is optional: only accepted as `--name=<value>`,
+	// [Option.Implicit] is used if the option is provided on its own
+	Optional bool
+	Implicit string
+	// File mode of file options: "r", "w", "a" or "rw"
+	Mode string
+	// Requires the path to exist and be a "file" or a "dir"
+	Exists string
+	// Requires the path to either be an existing file or to have an existing parent directory
+	Creatable bool
+	// Constraints of every value (or element), see [Option.Compile()]
+	Min     string
+	Max     string
+	MinLen  string
+	MaxLen  string
+	Pattern string
+	// Whether the option must be provided (a default value counts)
+	Required bool
+	// Names of the options that can't be provided together with this one
+	Conflicts []string
+	// Names of the options that must be provided (a default value counts) together with this one
+	Requires []string
+	// Name of the group of options, exactly one of which must be provided
+	OneOf string
+	// Names of all options in the [Option.OneOf] group, set by the runtime
+	OneOfGroup []string
+	// Whether the option is only displayed by --help-all
+	Hidden bool
+	// Warning message printed when the option is used
+	Deprecated string
+	// Title of the --help section the option is printed in, "Options" if empty
+	Group string
 
-	Type reflect.Type
-	Ref  *reflect.Value
+	// Name of the field of the struct the option is generated from
+	Field string
+	Type  reflect.Type
+	Ref   *reflect.Value
+
+	source  Source
+	paths   []string
+	files   []*os.File
+	min     reflect.Value
+	max     reflect.Value
+	minLen  int
+	maxLen  int
+	pattern *regexp.Regexp
+}
+
+func (option *Option) Flag() string {
+	return option.flag("")
 }
 
-func (option Option) Flag() string {
-	if option.Alt == "" {
-		return "--" + option.Name
+// Returns all names of the option, `suffix` is attached to the long name
+func (option *Option) flag(suffix string) string {
+	name := "--" + option.Name
+	if option.IsNegatable() {
+		name = "--[no-]" + option.Name
+	}
+	names := []string{name + suffix}
+	for _, alt := range option.Alts {
+		names = append(names, "-"+alt)
 	}
-	return fmt.Sprintf("--%s, -%s", option.Name, option.Alt)
+	for _, alias := range option.Aliases {
+		names = append(names, "--"+alias)
+	}
+	return strings.Join(names, ", ")
 }
 
-func (option Option) String() string {
-	if option.IsFlag() {
-		return option.Flag()
+func (option *Option) String() string {
+	usage := option.Flag()
+	switch {
+	case option.IsFlag():
+	case option.Optional:
+		usage = option.flag(fmt.Sprintf("[=%s]", option.placeholder()))
+	default:
+		usage = fmt.Sprintf("%s %s", option.Flag(), option.placeholder())
+	}
+	if notes := option.notes(); len(notes) != 0 {
+		return fmt.Sprintf("%s (%s)", usage, strings.Join(notes, ", "))
+	}
+	return usage
+}
+
+// Returns the default value and constraints displayed in the --help menu
+func (option *Option) notes() []string {
+	notes := []string{}
+	if option.IsCounter() {
+		notes = append(notes, "repeatable")
+	}
+	if option.Required {
+		notes = append(notes, "required")
+	}
+	if option.Default != "" {
+		notes = append(notes, "default: "+option.defaultText())
+	}
+	if option.Env != "" {
+		notes = append(notes, "env: "+option.Env)
+	}
+	for _, group := range [][2]string{
+		{"conflicts with", joinNames(option.Conflicts)},
+		{"requires", joinNames(option.Requires)},
+		{"one of", joinNames(option.OneOfGroup)},
+	} {
+		if group[1] != "" {
+			notes = append(notes, group[0]+": "+group[1])
+		}
+	}
+	for _, constraint := range [][2]string{
+		{"min", option.Min},
+		{"max", option.Max},
+		{"minlen", option.MinLen},
+		{"maxlen", option.MaxLen},
+		{"pattern", option.Pattern},
+	} {
+		if constraint[1] != "" {
+			notes = append(notes, constraint[0]+": "+constraint[1])
+		}
+	}
+	return notes
+}
+
+// Formats option names as `--a/--b`
+func joinNames(names []string) string {
+	flags := make([]string, len(names))
+	for i, name := range names {
+		flags[i] = "--" + name
+	}
+	return strings.Join(flags, "/")
+}
+
+// Returns the default value displayed in the --help menu.
+// Uses [Value.String()] of custom types
+func (option *Option) defaultText() string {
+	if !isValue(option.Type) {
+		return option.Default
+	}
+	value := reflect.New(option.Type).Interface().(Value)
+	if err := value.Set(option.Default); err != nil {
+		return option.Default
 	}
-	if option.Default == "" {
-		return fmt.Sprintf("%s <%s>", option.Flag(), option.Type.String())
+	return value.String()
+}
+
+// Returns the value placeholder displayed in the --help menu
+func (option *Option) placeholder() string {
+	if option.IsRepeatable() {
+		switch option.Type.Kind() {
+		case reflect.Slice:
+			return fmt.Sprintf("<%s>...", option.valueName(option.Type.Elem()))
+		case reflect.Map:
+			return fmt.Sprintf("<%s=%s>...", typeName(option.Type.Key()), option.valueName(option.Type.Elem()))
+		}
+	}
+	return fmt.Sprintf("<%s>", option.valueName(option.Type))
+}
+
+// Returns the choices or the type name of the value
+func (option *Option) valueName(typ reflect.Type) string {
+	if len(option.Choices) != 0 {
+		return strings.Join(option.Choices, "|")
+	}
+	return typeName(typ)
+}
+
+// Whether the option doesn't require a value, see [Option.SetFlag()]
+func (option *Option) IsFlag() bool {
+	return option.Type.Kind() == reflect.Bool || option.Type == boolPointerType || option.IsCounter()
+}
+
+// Whether the option is handled by the runtime itself, e.g. --help
+func (option *Option) IsBuiltin() bool {
+	return option.Ref == nil
+}
+
+// Whether the boolean flag can be turned off with `--no-<name>`
+func (option *Option) IsNegatable() bool {
+	return !option.IsBuiltin() && option.IsFlag() && !option.IsCounter()
+}
+
+// Whether every occurrence of the flag increments its value
+func (option *Option) IsCounter() bool {
+	if !option.Count {
+		return false
+	}
+	switch option.Type.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+// Whether the option can be provided multiple times, appending to its value
+func (option *Option) IsRepeatable() bool {
+	if isScalar(option.Type) {
+		return false
+	}
+	switch option.Type.Kind() {
+	case reflect.Slice, reflect.Map:
+		return true
+	}
+	return false
+}
+
+// Returns where the current value came from
+func (option *Option) Source() Source {
+	return option.source
+}
+
+// Sets the value provided by the user
+func (option *Option) Set(value string) error {
+	return option.set(SourceInput, value)
+}
+
+// Sets the value of the environment variable, which takes precedence over the default value
+func (option *Option) SetEnv(value string) error {
+	return option.set(SourceEnv, value)
+}
+
+// Seeds the option with its default value.
+// Empty string resets the option without counting as provided
+func (option *Option) SetDefault(value string) error {
+	err := option.set(SourceDefault, value)
+	if value == "" {
+		option.source = SourceNone
 	}
-	return fmt.Sprintf("%s <%s> (default: %s)", option.Flag(), option.Type.String(), option.Default)
+	return err
+}
+
+// Whether the option got a value from any source, including its default
+func (option *Option) IsProvided() bool {
+	return option.source != SourceNone
+}
+
+// Whether the option was explicitly provided, i.e. not by its default
+func (option *Option) IsExplicit() bool {
+	return option.source > SourceDefault
 }
 
-func (option Option) IsFlag() bool {
-	return option.Type.Kind() == reflect.Bool
+// Whether the option was explicitly provided and isn't a boolean flag set to false
+func (option *Option) IsEnabled() bool {
+	if !option.IsExplicit() || option.Ref == nil {
+		return option.IsExplicit()
+	}
+	switch {
+	case option.Type.Kind() == reflect.Bool:
+		return option.Ref.Bool()
+	case option.Type == boolPointerType:
+		return !option.Ref.IsNil() && option.Ref.Elem().Bool()
+	}
+	return true
 }
 
-func (option Option) Set(value string) error {
+func (option *Option) set(source Source, value string) error {
 	if option.Ref == nil {
 		return errors.New("(internal) option.Ref is nil!")
 	}
 
-	switch option.Type.Kind() {
+	// Files are only opened by [Option.Open()]
+	if option.IsFile() {
+		return option.setPaths(source, value)
+	}
 
-	case reflect.String:
-		option.Ref.SetString(value)
+	// Custom types are set in place, allowing them to accumulate values.
+	// Values from a lower priority source are discarded first
+	if isValue(option.Type) {
+		if option.source < source {
+			option.Ref.SetZero()
+		}
+		if source == SourceDefault && value == "" {
+			return nil
+		}
+		if err := option.check(value); err != nil {
+			return err
+		}
+		if err := option.Ref.Addr().Interface().(Value).Set(value); err != nil {
+			return err
+		}
+		if err := option.checkRange(value, *option.Ref); err != nil {
+			return err
+		}
+		option.source = source
+		return nil
+	}
+
+	if option.IsRepeatable() {
+		switch option.Type.Kind() {
+		case reflect.Slice:
+			return option.appendValues(source, value)
+		case reflect.Map:
+			return option.putPairs(source, value)
+		}
+	}
+
+	// Options without a default value start from zero, e.g. pointers stay nil unless a value is provided
+	if source == SourceDefault && value == "" {
+		option.Ref.SetZero()
+		option.source = SourceNone
 		return nil
+	}
+
+	result, err := option.parseChecked(option.Type, value)
+	if err != nil {
+		return err
+	}
+	option.Ref.Set(result)
+	option.source = source
+	return nil
+}
+
+// [ChoiceError] is returned when the value isn't one of [Option.Choices]
+type ChoiceError struct {
+	Value   string
+	Choices []string
+}
+
+func (err ChoiceError) Error() string {
+	return fmt.Sprintf("%q is not one of: %s", err.Value, strings.Join(err.Choices, ", "))
+}
 
-	case reflect.Int:
-		num, err := strconv.ParseInt(value, 10, 64)
+// Validates a single value (or an element) before it's parsed
+func (option *Option) check(value string) error {
+	if err := option.checkChoice(value); err != nil {
+		return err
+	}
+	if err := option.checkText(value); err != nil {
+		return err
+	}
+	return option.checkPath(value)
+}
+
+// Parses a single value (or an element) and validates it against all constraints
+func (option *Option) parseChecked(typ reflect.Type, value string) (reflect.Value, error) {
+	if err := option.check(value); err != nil {
+		return reflect.Value{}, err
+	}
+	result, err := option.parse(typ, value)
+	if err != nil {
+		return result, err
+	}
+	return result, option.checkRange(value, result)
+}
+
+func (option *Option) checkChoice(value string) error {
+	if len(option.Choices) == 0 || slices.Contains(option.Choices, value) {
+		return nil
+	}
+	return ChoiceError{Value: value, Choices: option.Choices}
+}
+
+// Splits the value of a slice/map option by [Option.Sep]
+func (option *Option) split(source Source, value string) []string {
+	switch {
+	case source == SourceDefault && value == "":
+		return []string{}
+	case option.Sep == "":
+		return []string{value}
+	}
+	return strings.Split(value, option.Sep)
+}
+
+// Appends one or more (split by [Option.Sep]) elements to a slice option.
+// Values from a lower priority source are discarded first
+func (option *Option) appendValues(source Source, value string) error {
+	parts := option.split(source, value)
+	elements := make([]reflect.Value, 0, len(parts))
+	for _, part := range parts {
+		element, err := option.parseChecked(option.Type.Elem(), part)
 		if err != nil {
 			return err
 		}
-		option.Ref.SetInt(num)
-		return nil
+		elements = append(elements, element)
+	}
+
+	if option.source < source {
+		option.Ref.Set(reflect.MakeSlice(option.Type, 0, len(elements)))
+	}
+	option.Ref.Set(reflect.Append(*option.Ref, elements...))
+	option.source = source
+	return nil
+}
 
-	case reflect.Float64, reflect.Float32:
-		num, err := strconv.ParseFloat(value, 64)
+// Puts one or more (split by [Option.Sep]) `key=value` pairs into a map option.
+// Values from a lower priority source are discarded first
+func (option *Option) putPairs(source Source, value string) error {
+	parts := option.split(source, value)
+	keys := make([]reflect.Value, 0, len(parts))
+	values := make([]reflect.Value, 0, len(parts))
+	for _, part := range parts {
+		rawKey, rawValue, found := strings.Cut(part, "=")
+		if !found {
+			return fmt.Errorf("expected a `key=value` pair, got %q", part)
+		}
+		key, err := option.parse(option.Type.Key(), rawKey)
 		if err != nil {
 			return err
 		}
-		option.Ref.SetFloat(num)
-		return nil
+		elem, err := option.parseChecked(option.Type.Elem(), rawValue)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values = append(values, elem)
 	}
 
-	return fmt.Errorf("unknown option type %q", option.Type)
+	if option.source < source || option.Ref.IsNil() {
+		option.Ref.Set(reflect.MakeMapWithSize(option.Type, len(keys)))
+	}
+	for i, key := range keys {
+		option.Ref.SetMapIndex(key, values[i])
+	}
+	option.source = source
+	return nil
 }
 
-func (option Option) SetFlag() {
-	if option.Type.Kind() != reflect.Bool {
+// Sets the flag to true or increments the counter
+func (option *Option) SetFlag() {
+	if !option.IsFlag() {
 		panic("trying to call SetFlag() on an option that isn't actually a flag")
 	}
-	option.Ref.SetBool(true)
+	switch {
+	case option.IsCounter():
+		// Counting starts over if the value came from a lower priority source
+		count := option.Ref.Int() + 1
+		if option.source < SourceInput {
+			count = 1
+		}
+		if !option.Ref.OverflowInt(count) {
+			option.Ref.SetInt(count)
+		}
+	case option.Type == boolPointerType:
+		flag := true
+		option.Ref.Set(reflect.ValueOf(&flag))
+	default:
+		option.Ref.SetBool(true)
+	}
+	option.source = SourceInput
 }
@@ -29,8 +29,11 @@ func (omap *OrderedMap[V]) Clear() {
 	}
 }
 
+// Adds the value, replacing the existing one without changing the order
 func (omap *OrderedMap[V]) Add(key string, value V) {
-	omap.keys = append(omap.keys, key)
+	if _, exists := omap.values[key]; !exists {
+		omap.keys = append(omap.keys, key)
+	}
 	omap.values[key] = value
 }
 
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// [Value] is implemented by custom option types, see [parsex.Value]
+type Value interface {
+	// Parses the raw string and stores the result
+	Set(string) error
+	// Returns the human-readable representation of the value
+	String() string
+	// Returns the name of the type used in the --help menu
+	Type() string
+}
+
+var (
+	valueType           = reflect.TypeOf((*Value)(nil)).Elem()
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+	bigIntType   = reflect.TypeOf((*big.Int)(nil))
+	bigFloatType = reflect.TypeOf((*big.Float)(nil))
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	locationType = reflect.TypeOf((*time.Location)(nil))
+	addrType     = reflect.TypeOf(netip.Addr{})
+	prefixType   = reflect.TypeOf(netip.Prefix{})
+	addrPortType = reflect.TypeOf(netip.AddrPort{})
+	ipType       = reflect.TypeOf(net.IP{})
+	urlType      = reflect.TypeOf((*url.URL)(nil))
+	regexpType   = reflect.TypeOf((*regexp.Regexp)(nil))
+	fileModeType = reflect.TypeOf(os.FileMode(0))
+)
+
+// Human-readable names of the built-in types used in the --help menu
+var builtinTypeNames = map[reflect.Type]string{
+	bigIntType:   "bigint",
+	bigFloatType: "bigfloat",
+	durationType: "duration",
+	timeType:     "time",
+	locationType: "location",
+	addrType:     "addr",
+	prefixType:   "prefix",
+	addrPortType: "addr:port",
+	ipType:       "ip",
+	urlType:      "url",
+	regexpType:   "regexp",
+	fileModeType: "mode",
+	fileType:     "file",
+	readerType:   "file",
+	writerType:   "file",
+}
+
+// Returns a human-readable name of the type used in the --help menu
+func typeName(typ reflect.Type) string {
+	if isValue(typ) {
+		return reflect.New(typ).Interface().(Value).Type()
+	}
+	if name, exists := builtinTypeNames[typ]; exists {
+		return name
+	}
+
+	if typ.Kind() == reflect.Pointer {
+		return typeName(typ.Elem())
+	}
+	return typ.String()
+}
+
+// Whether the type is parsed from a single string as a whole,
+// even if it's a slice (e.g. [net.IP]) or a struct
+func isScalar(typ reflect.Type) bool {
+	_, builtin := builtinTypeNames[typ]
+	return builtin || isValue(typ) || isTextUnmarshaler(typ)
+}
+
+// Whether the fields of the struct (or the pointer to it) should be registered as separate options
+func IsOptionGroup(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer && !isScalar(typ) {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct && !isScalar(typ)
+}
+
+// Whether the pointer to the type implements [Value]
+func isValue(typ reflect.Type) bool {
+	return reflect.PointerTo(typ).Implements(valueType)
+}
+
+// Whether the pointer to the type implements [encoding.TextUnmarshaler]
+func isTextUnmarshaler(typ reflect.Type) bool {
+	return reflect.PointerTo(typ).Implements(textUnmarshalerType)
+}
+
+// Converts a raw string into a new value of the given type.
+//
+// Types implementing [Value] take precedence, followed by the built-in types,
+// [encoding.TextUnmarshaler] and finally the kind of the type.
+//
+// Integers accept `0x`/`0o`/`0b` prefixes and `_` digit separators,
+// [time.Time] is parsed using [Option.Layout] (RFC 3339 by default)
+func (option *Option) parse(typ reflect.Type, value string) (reflect.Value, error) {
+	result := reflect.New(typ).Elem()
+
+	if isValue(typ) {
+		if err := result.Addr().Interface().(Value).Set(value); err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+
+	switch typ {
+
+	case bigIntType:
+		num, ok := new(big.Int).SetString(value, 0)
+		if !ok {
+			return result, fmt.Errorf("invalid integer %q", value)
+		}
+		result.Set(reflect.ValueOf(num))
+		return result, nil
+
+	case bigFloatType:
+		num, ok := new(big.Float).SetString(value)
+		if !ok {
+			return result, fmt.Errorf("invalid number %q", value)
+		}
+		result.Set(reflect.ValueOf(num))
+		return result, nil
+
+	case durationType:
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return result, err
+		}
+		result.SetInt(int64(duration))
+		return result, nil
+
+	case timeType:
+		layout := option.Layout
+		if layout == "" {
+			layout = time.RFC3339
+		}
+		moment, err := time.Parse(layout, value)
+		if err != nil {
+			return result, err
+		}
+		result.Set(reflect.ValueOf(moment))
+		return result, nil
+
+	case locationType:
+		return wrap(time.LoadLocation(value))
+
+	case addrType:
+		return wrap(netip.ParseAddr(value))
+
+	case prefixType:
+		return wrap(netip.ParsePrefix(value))
+
+	case addrPortType:
+		return wrap(netip.ParseAddrPort(value))
+
+	case ipType:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return result, fmt.Errorf("invalid IP address %q", value)
+		}
+		result.Set(reflect.ValueOf(ip))
+		return result, nil
+
+	case urlType:
+		return wrap(url.Parse(value))
+
+	case regexpType:
+		return wrap(regexp.Compile(value))
+
+	case fileModeType:
+		// Always octal, with an optional "0o" prefix
+		octal := strings.TrimPrefix(strings.TrimPrefix(value, "0o"), "0O")
+		mode, err := strconv.ParseUint(octal, 8, 32)
+		if err != nil {
+			return result, err
+		}
+		result.SetUint(mode)
+		return result, nil
+	}
+
+	if isTextUnmarshaler(typ) {
+		unmarshaler := result.Addr().Interface().(encoding.TextUnmarshaler)
+		if err := unmarshaler.UnmarshalText([]byte(value)); err != nil {
+			return result, err
+		}
+		return result, nil
+	}
+
+	switch typ.Kind() {
+
+	case reflect.String:
+		result.SetString(value)
+		return result, nil
+
+	case reflect.Bool:
+		flag, err := parseBool(value)
+		if err != nil {
+			return result, err
+		}
+		result.SetBool(flag)
+		return result, nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		num, err := strconv.ParseInt(value, 0, typ.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetInt(num)
+		return result, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		num, err := strconv.ParseUint(value, 0, typ.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetUint(num)
+		return result, nil
+
+	case reflect.Float64, reflect.Float32:
+		num, err := strconv.ParseFloat(value, typ.Bits())
+		if err != nil {
+			return result, err
+		}
+		result.SetFloat(num)
+		return result, nil
+
+	case reflect.Pointer:
+		elem, err := option.parse(typ.Elem(), value)
+		if err != nil {
+			return result, err
+		}
+		result.Set(reflect.New(typ.Elem()))
+		result.Elem().Set(elem)
+		return result, nil
+	}
+
+	return result, fmt.Errorf("unknown option type %q", typ)
+}
+
+// Strictly parses `true|false|1|0|yes|no` (case-insensitive)
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "1", "yes":
+		return true, nil
+	case "false", "0", "no":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid boolean %q, expected true|false|1|0|yes|no", value)
+}
+
+// Wraps the result of a parsing function into [reflect.Value]
+func wrap[T any](value T, err error) (reflect.Value, error) {
+	return reflect.ValueOf(value), err
+}
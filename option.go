@@ -0,0 +1,62 @@
+package parsex
+
+import (
+	"slices"
+
+	"github.com/bbfh-dev/parsex/v2/internal"
+)
+
+// [Option] describes an option generated from a field of [Program.Data].
+//
+// Exposes read-only metadata for shell completion and documentation generators.
+type Option struct {
+	Name string
+	// Single letter alternatives, e.g. `-o`
+	Alts []string
+	// Long alternatives, e.g. `--output-file`
+	Aliases []string
+	Desc    string
+	Default string
+	// Name of the environment variable used if the option isn't provided
+	Env string
+	// Values allowed for the option (or each of its elements), any if empty
+	Choices []string
+	// Title of the --help section the option is printed in, "Options" if empty
+	Group string
+	// Help line of the option, e.g. `--format, -f <json|yaml> (default: json)`
+	Usage string
+	// Whether the option doesn't take a value
+	Flag       bool
+	Repeatable bool
+	Required   bool
+	Hidden     bool
+	// Warning message printed when the option is used, empty if it isn't deprecated
+	Deprecated string
+}
+
+// Performs preprocessing and returns all options of the program in order
+func (runtime *runtimeType) Options() ([]Option, error) {
+	if err := runtime.preprocess(); err != nil {
+		return nil, err
+	}
+	options := []Option{}
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		options = append(options, Option{
+			Name:       option.Name,
+			Alts:       slices.Clone(option.Alts),
+			Aliases:    slices.Clone(option.Aliases),
+			Desc:       option.Desc,
+			Default:    option.Default,
+			Env:        option.Env,
+			Choices:    slices.Clone(option.Choices),
+			Group:      option.Group,
+			Usage:      option.String(),
+			Flag:       option.IsFlag(),
+			Repeatable: option.IsRepeatable() || option.IsCounter(),
+			Required:   option.Required,
+			Hidden:     option.Hidden,
+			Deprecated: option.Deprecated,
+		})
+	})
+	return options, nil
+}
@@ -10,9 +10,51 @@ type Program struct {
 	// All input flags will be stored in the struct,
 	// --help menu will be automatically generated.
 	//
+	// Fields of custom types must implement [Value] or [encoding.TextUnmarshaler].
+	// Pointer fields stay nil unless the option is provided or has a default value.
+	//
+	// Implement [Validator] to validate the options before [Program.Exec] is called.
+	//
 	// Use the following field tags:
 	//
 	// `alt:"<single letter alternative use>" desc:"<description of the option>`
+	//
+	// `alt:"<comma-separated single letter alternatives>"`, `aliases:"<comma-separated long alternatives>"`
+	//
+	// `group:"<title of the --help section>"` ("Options" by default)
+	//
+	// `name:"<name of the option>"` (kebab-case field name by default), `parsex:"-"` (skip the field)
+	//
+	// `default:"<default value>"`
+	//
+	// `env:"<environment variable used if the option isn't provided>"` (takes precedence over `default`)
+	//
+	// `sep:"<separator used to split values of []T and map[K]V options, \",\" by default>"` (empty disables splitting)
+	//
+	// `layout:"<layout of time.Time options, RFC 3339 by default>"`
+	//
+	// `choices:"<comma-separated list of allowed values>"`
+	//
+	// `count:"true"` (every occurrence of an integer flag increments it, e.g. `-vvv`)
+	//
+	// `optional:"<value used if the option is provided without one>"` (e.g. `--color` vs `--color=always`)
+	//
+	// `mode:"r|w|a|rw"` (mode of *os.File options, "-" stands for stdin/stdout, opened after all checks pass)
+	//
+	// `exists:"file|dir"`, `creatable:"true"` (validate path options)
+	//
+	// `required:"true"` (the option must be provided, a default value counts)
+	//
+	// `hidden:"true"`, `deprecated:"<warning message>"` (only displayed by --help-all)
+	//
+	// `prefix:"<prefix of the options of a nested struct>"` (field name by default, none for embedded structs)
+	//
+	// `conflicts:"<names of options>"`, `requires:"<names of options>"`, `oneof:"<name of the group>"`
+	//
+	// `min:"<n>"`, `max:"<n>"`, `minlen:"<n>"`, `maxlen:"<n>"`, `pattern:"<regexp>"` (validate every value)
+	//
+	// `arg:"<index>"`, `arg:"rest"` (bind the field to positional arguments instead of an option,
+	// the indices must be declared in order and "rest" must be a slice that isn't split by default)
 	Data any
 	// The name of the executable / command
 	Name string
@@ -1,6 +1,7 @@
 package parsex
 
 import (
+	"io"
 	"os"
 	"strings"
 
@@ -18,27 +19,43 @@ type runtimeType struct {
 	// SemVer is adviced. Use [Program.SetVersion(...)] to edit
 	version string
 	// (Optional) Positional arguments for the program
-	posArgs []string
+	posArgs []Arg
 	// (Optional) Other subcommands
 	branches *internal.OrderedMap[*runtimeType]
+	// (Optional) Titles of the --help sections of the subcommands
+	branchGroups map[string]string
+	// Deprecation warnings are written here. Use [Program.SetWarningWriter(...)] to edit
+	warnings io.Writer
+	// (Optional) Prefix of the environment variables of the options. Use [Program.SetEnvPrefix(...)] to edit
+	envPrefix string
 
 	// --- Internal
-	genOptions    *internal.OrderedMap[internal.Option]
-	genOptionAlts map[string]string
-	genReqPosArgs []string
+	genOptions       *internal.OrderedMap[*internal.Option]
+	genOptionAlts    map[string]string
+	genOptionAliases map[string]string
+	genOptionNegs    map[string]string
+	// Options bound to the positional arguments, see [runtimeType.registerArg()]
+	genArgs    []*internal.Option
+	genRestArg *internal.Option
+	// Whether [runtimeType.posArgs] were derived from the options bound to the positional arguments
+	genPosArgs bool
 }
 
 func newRuntime(program *Program) *runtimeType {
 	return &runtimeType{
-		data:          program.Data,
-		exec:          internal.NewContextExecutable(program.Exec),
-		name:          program.Name,
-		desc:          program.Desc,
-		version:       "",
-		posArgs:       []string{},
-		branches:      internal.NewOrderedMap[*runtimeType](),
-		genOptions:    internal.NewOrderedMap[internal.Option](),
-		genOptionAlts: map[string]string{},
+		data:             program.Data,
+		exec:             internal.NewContextExecutable(program.Exec),
+		name:             program.Name,
+		desc:             program.Desc,
+		version:          "",
+		posArgs:          []Arg{},
+		branches:         internal.NewOrderedMap[*runtimeType](),
+		branchGroups:     map[string]string{},
+		warnings:         os.Stderr,
+		genOptions:       internal.NewOrderedMap[*internal.Option](),
+		genOptionAlts:    map[string]string{},
+		genOptionAliases: map[string]string{},
+		genOptionNegs:    map[string]string{},
 	}
 }
 
@@ -50,20 +67,46 @@ func (runtime *runtimeType) SetVersion(version string) *runtimeType {
 	return runtime
 }
 
+// Sets the writer of deprecation warnings (os.Stderr by default).
+func (runtime *runtimeType) SetWarningWriter(writer io.Writer) *runtimeType {
+	runtime.warnings = writer
+	return runtime
+}
+
+// Sets the prefix of the environment variables of the options.
+//
+// Every option without an `env` tag falls back to `<PREFIX>_<NAME>`, e.g. `APP_DB_HOST` for `--db-host`.
+func (runtime *runtimeType) SetEnvPrefix(prefix string) *runtimeType {
+	runtime.envPrefix = prefix
+	return runtime
+}
+
 // Specifies program positional arguments.
 //
 // - Include `?` in the argument if it's optional;
 //
 // - Suffix with `...` in the argument if its variadic;
 //
-// Prints the arguments in --help menu as provided.
+// Prints the arguments in --help menu as provided. See [runtimeType.SetArgs(...)] for details.
 func (runtime *runtimeType) SetPosArgs(args ...string) *runtimeType {
-	runtime.posArgs = args
+	posArgs := make([]Arg, 0, len(args))
 	for _, arg := range args {
-		if !strings.Contains(arg, "?") {
-			runtime.genReqPosArgs = append(runtime.genReqPosArgs, arg)
-		}
+		posArgs = append(posArgs, parseArg(arg))
 	}
+	return runtime.SetArgs(posArgs...)
+}
+
+// Specifies program positional arguments.
+//
+// Optional arguments must come after the required ones and only the last argument can be variadic.
+// Providing more arguments than accepted is an error unless no arguments are specified.
+//
+// Prints the "Arguments:" section in --help menu if any of them has a description.
+//
+// By default the arguments are derived from `arg` fields of [Program.Data].
+func (runtime *runtimeType) SetArgs(args ...Arg) *runtimeType {
+	runtime.posArgs = args
+	runtime.genPosArgs = false
 	return runtime
 }
 
@@ -75,19 +118,38 @@ func (runtime *runtimeType) RegisterCommand(command *runtimeType) *runtimeType {
 	return runtime
 }
 
+// Registers branches/subcommands just like [runtimeType.RegisterCommand(...)].
+//
+// Prints the commands in a separate --help section titled `group`.
+func (runtime *runtimeType) RegisterCommandGroup(group string, commands ...*runtimeType) *runtimeType {
+	for _, command := range commands {
+		runtime.RegisterCommand(command)
+		runtime.branchGroups[command.name] = group
+	}
+	return runtime
+}
+
 // Run processes options, validates them, and then executes the command.
 func (runtime *runtimeType) Run(inputArgs []string) error {
 	runtime.exec.Clear()
 	if err := runtime.preprocess(); err != nil {
 		return err
 	}
+	defer runtime.closeFiles()
 
 iterate:
 	for i := 0; i < len(inputArgs); i++ {
 		arg := inputArgs[i]
-		if !strings.HasPrefix(arg, "-") {
+		// A bare "-" stands for stdin/stdout.
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
 			// Branch or positional argument.
 			if branch, exists := runtime.branches.Get(arg); exists {
+				// Environment variables of this program apply just like the options provided before the branch
+				if !isShortcut(inputArgs[i+1:]) {
+					if err := runtime.applyEnv(); err != nil {
+						return err
+					}
+				}
 				return branch.Run(inputArgs[i+1:])
 			}
 			runtime.exec.AddArg(arg)
@@ -97,7 +159,10 @@ iterate:
 		// Handle help and version shortcuts.
 		switch arg {
 		case "--help":
-			runtime.printHelp(os.Stdout)
+			runtime.printHelp(os.Stdout, false)
+			return nil
+		case "--help-all":
+			runtime.printHelp(os.Stdout, true)
 			return nil
 		case "--version":
 			runtime.PrintVersion(os.Stdout)
@@ -117,17 +182,32 @@ iterate:
 			return err
 		}
 	}
-	lenProv := len(runtime.exec.Args)
-	lenReq := len(runtime.genReqPosArgs)
-	if lenProv < lenReq {
-		return ErrInput{
-			ErrKind:     ErrKindNotEnoughArgs,
-			Name:        runtime.name,
-			RequiredLen: lenReq,
-			ProvidedLen: lenProv,
-			ExecArgs:    runtime.exec.Args,
-			ArgPrinter:  runtime.printArgs,
-		}
+
+	// Environment variables are applied after --help and --version are handled
+	if err := runtime.applyEnv(); err != nil {
+		return err
+	}
+	if err := runtime.checkArgCount(); err != nil {
+		return err
+	}
+
+	if err := runtime.bindArgs(); err != nil {
+		return err
+	}
+	runtime.warnDeprecated()
+
+	if err := runtime.checkRequired(); err != nil {
+		return err
+	}
+	if err := runtime.checkGroups(); err != nil {
+		return err
+	}
+	if err := runtime.validateData(); err != nil {
+		return err
+	}
+	// Files are opened last, see [runtimeType.openFiles()]
+	if err := runtime.openFiles(); err != nil {
+		return err
 	}
 
 	if runtime.exec.Function == nil {
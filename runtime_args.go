@@ -0,0 +1,170 @@
+package parsex
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/bbfh-dev/parsex/v2/internal"
+)
+
+// registerArg binds the option to the positional argument at `index` or to the rest of them.
+//
+// Indices must be declared in order, starting with 0.
+func (runtime *runtimeType) registerArg(index string, option *internal.Option) error {
+	if index == "rest" {
+		if runtime.genRestArg != nil {
+			return fmt.Errorf("arg: %q is already bound to <%s>", index, runtime.genRestArg.Name)
+		}
+		if !option.IsRepeatable() || option.Type.Kind() != reflect.Slice {
+			return fmt.Errorf("arg: %q requires a slice, got %q", index, option.Type)
+		}
+		runtime.genRestArg = option
+		return nil
+	}
+
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		return fmt.Errorf("arg: %w", err)
+	}
+	if i != len(runtime.genArgs) {
+		return fmt.Errorf("arg: expected index %d, got %d", len(runtime.genArgs), i)
+	}
+	runtime.genArgs = append(runtime.genArgs, option)
+	return nil
+}
+
+// argOptions returns all options bound to the positional arguments.
+func (runtime *runtimeType) argOptions() []*internal.Option {
+	options := runtime.genArgs
+	if runtime.genRestArg != nil {
+		options = append(options[:len(options):len(options)], runtime.genRestArg)
+	}
+	return options
+}
+
+// derivePosArgs sets [runtimeType.posArgs] from the options bound to the positional arguments.
+//
+// Arguments with a default value or of a pointer type are optional, as well as the rest of them.
+func (runtime *runtimeType) derivePosArgs() {
+	runtime.posArgs = []Arg{}
+	for _, option := range runtime.genArgs {
+		runtime.posArgs = append(runtime.posArgs, Arg{
+			Name:     option.Name,
+			Desc:     option.Desc,
+			Optional: option.Default != "" || option.Type.Kind() == reflect.Pointer,
+		})
+	}
+	if runtime.genRestArg != nil {
+		runtime.posArgs = append(runtime.posArgs, Arg{
+			Name:     runtime.genRestArg.Name,
+			Desc:     runtime.genRestArg.Desc,
+			Optional: true,
+			Variadic: true,
+		})
+	}
+	runtime.genPosArgs = len(runtime.posArgs) != 0
+}
+
+// checkArgs validates the order of [runtimeType.posArgs].
+func (runtime *runtimeType) checkArgs() error {
+	for i, arg := range runtime.posArgs {
+		err := ErrProgramData{
+			Name: runtime.name,
+			Type: reflect.TypeOf(runtime.data),
+			Arg:  arg.Name,
+		}
+		switch {
+		case arg.Variadic && i != len(runtime.posArgs)-1:
+			err.ErrKind = ErrKindVariadicNotLast
+			return err
+		case !arg.Optional && i != 0 && runtime.posArgs[i-1].Optional:
+			err.ErrKind = ErrKindRequiredAfterOptional
+			return err
+		case arg.Variadic && arg.Max != 0 && arg.Max < arg.Min:
+			err.ErrKind = ErrKindInvalidArgRange
+			err.Err = errors.New("max is less than min")
+			return err
+		case arg.Optional && arg.Min != 0:
+			err.ErrKind = ErrKindInvalidArgRange
+			err.Err = errors.New("optional arguments can't have a min")
+			return err
+		}
+	}
+	return nil
+}
+
+// argCounts returns the number of positional arguments that the program requires and accepts.
+// The latter is negative if there's no limit.
+func (runtime *runtimeType) argCounts() (int, int) {
+	if len(runtime.posArgs) == 0 {
+		return 0, -1
+	}
+	minCount, maxCount := 0, 0
+	for _, arg := range runtime.posArgs {
+		minCount += arg.minCount()
+		if arg.maxCount() == 0 {
+			maxCount = -1
+		} else if maxCount >= 0 {
+			maxCount += arg.maxCount()
+		}
+	}
+	return minCount, maxCount
+}
+
+// checkArgCount validates the number of provided positional arguments.
+func (runtime *runtimeType) checkArgCount() error {
+	minCount, maxCount := runtime.argCounts()
+	err := ErrInput{
+		Name:        runtime.name,
+		RequiredLen: minCount,
+		MaxLen:      maxCount,
+		ProvidedLen: len(runtime.exec.Args),
+		ExecArgs:    runtime.exec.Args,
+		ArgPrinter:  runtime.printArgs,
+	}
+	switch {
+	case err.ProvidedLen < minCount:
+		err.ErrKind = ErrKindNotEnoughArgs
+		return err
+	case maxCount >= 0 && err.ProvidedLen > maxCount:
+		err.ErrKind = ErrKindTooManyArgs
+		return err
+	}
+	return nil
+}
+
+// bindArgs sets the options bound to the positional arguments.
+// Positional arguments are still passed to [Program.Exec] as they are.
+func (runtime *runtimeType) bindArgs() error {
+	for i, arg := range runtime.exec.Args {
+		option := runtime.genRestArg
+		if i < len(runtime.genArgs) {
+			option = runtime.genArgs[i]
+		}
+		if option == nil {
+			return nil
+		}
+		if err := option.Set(arg); err != nil {
+			return runtime.argError(option, err)
+		}
+	}
+	return nil
+}
+
+// argError wraps the error returned by [internal.Option.Set()] into [ErrInput].
+func (runtime *runtimeType) argError(option *internal.Option, err error) ErrInput {
+	minCount, maxCount := runtime.argCounts()
+	return ErrInput{
+		ErrKind:     ErrKindInvalidArg,
+		Name:        runtime.name,
+		RequiredLen: minCount,
+		MaxLen:      maxCount,
+		ProvidedLen: len(runtime.exec.Args),
+		ExecArgs:    runtime.exec.Args,
+		ArgPrinter:  runtime.printArgs,
+		Arg:         option.Name,
+		Err:         err,
+	}
+}
@@ -0,0 +1,48 @@
+package parsex
+
+import (
+	"os"
+
+	"github.com/bbfh-dev/parsex/v2/internal"
+)
+
+// applyEnv sets the options from their environment variables.
+//
+// Options provided by the input are skipped, since it takes precedence.
+// Empty variables are ignored.
+func (runtime *runtimeType) applyEnv() error {
+	var err error
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if err != nil || option.Env == "" || option.IsExplicit() {
+			return
+		}
+		value, exists := os.LookupEnv(option.Env)
+		if !exists || value == "" {
+			return
+		}
+		if setErr := option.SetEnv(value); setErr != nil {
+			err = ErrOption{
+				ErrKind: ErrKindInvalidEnv,
+				Name:    runtime.name,
+				Option:  "--" + name,
+				Err:     setErr,
+				Env:     option.Env,
+			}
+		}
+	})
+	return err
+}
+
+// isShortcut checks whether the input asks for --help, --help-all or --version,
+// which must work regardless of the environment variables.
+func isShortcut(inputArgs []string) bool {
+	for _, arg := range inputArgs {
+		switch arg {
+		case "--help", "--help-all", "--version":
+			return true
+		case "--":
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package parsex
+
+import "github.com/bbfh-dev/parsex/v2/internal"
+
+// openFiles opens the files of the options and positional arguments.
+//
+// Unlike other values they aren't opened while parsing the input,
+// so that printing --help or any invalid input doesn't create or truncate any files.
+func (runtime *runtimeType) openFiles() error {
+	var err error
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if err != nil {
+			return
+		}
+		if openErr := option.Open(); openErr != nil {
+			err = runtime.settingError(name, openErr)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, option := range runtime.argOptions() {
+		if err := option.Open(); err != nil {
+			return runtime.argError(option, err)
+		}
+	}
+	return nil
+}
+
+// closeFiles closes all files opened by the options and positional arguments.
+func (runtime *runtimeType) closeFiles() {
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		option.Close()
+	})
+	for _, option := range runtime.argOptions() {
+		option.Close()
+	}
+}
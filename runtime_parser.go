@@ -3,46 +3,33 @@ package parsex
 import (
 	"errors"
 	"strings"
+
+	"github.com/bbfh-dev/parsex/v2/internal"
 )
 
 // processLongOption handles options starting with "--".
 func (runtime *runtimeType) processLongOption(arg string, i *int, inputArgs []string) error {
 	// Remove "--" prefix.
 	optionStr := arg[2:]
-	var name, value string
 
 	if parts := strings.SplitN(optionStr, "=", 2); len(parts) == 2 {
-		name = parts[0]
-		value = parts[1]
-		return runtime.setOption(name, value)
+		return runtime.setOption(runtime.resolveAlias(parts[0]), parts[1])
 	}
 
-	name = optionStr
+	name := runtime.resolveAlias(optionStr)
 	option, exists := runtime.genOptions.Get(name)
 	if !exists {
-		return ErrOption{
-			ErrKind: ErrKindUnknownOption,
-			Name:    runtime.name,
-			Option:  arg,
-			Err:     nil,
+		if negated, exists := runtime.genOptionNegs[name]; exists {
+			return runtime.setOption(negated, "false")
 		}
-	}
-	if option.IsFlag() {
-		option.SetFlag()
-		return nil
-	}
-
-	*i++
-	if *i >= len(inputArgs) {
 		return ErrOption{
-			ErrKind: ErrKindOptionNeedsValue,
+			ErrKind: ErrKindUnknownOption,
 			Name:    runtime.name,
 			Option:  arg,
 			Err:     nil,
 		}
 	}
-	value = inputArgs[*i]
-	return runtime.setOption(name, value)
+	return runtime.processBareOption(option, name, arg, i, inputArgs)
 }
 
 // processShortOption handles options starting with a single "-".
@@ -53,27 +40,17 @@ func (runtime *runtimeType) processShortOption(arg string, i *int, inputArgs []s
 
 	if strings.Contains(optionStr, "=") {
 		parts := strings.SplitN(optionStr, "=", 2)
-		name, value := parts[0], parts[1]
+		name, value := runtime.resolveAlt(parts[0]), parts[1]
 		return runtime.setOption(name, value)
 	}
 
-	name := optionStr
+	name := runtime.resolveAlt(optionStr)
 	option, exists := runtime.genOptions.Get(name)
 	if exists {
-		if option.IsFlag() {
-			option.SetFlag()
-			return nil
-		}
-		*i++
-		if *i >= len(inputArgs) {
-			return ErrOption{
-				ErrKind: ErrKindOptionNeedsValue,
-				Name:    runtime.name,
-				Option:  arg,
-				Err:     nil,
-			}
-		}
-		return runtime.setOption(name, inputArgs[*i])
+		return runtime.processBareOption(option, name, arg, i, inputArgs)
+	}
+	if negated, exists := runtime.genOptionNegs[name]; exists {
+		return runtime.setOption(negated, "false")
 	}
 
 	// Each character in the cluster should map to a flag.
@@ -89,7 +66,7 @@ func (runtime *runtimeType) processShortOption(arg string, i *int, inputArgs []s
 			}
 		}
 		option, exists := runtime.genOptions.Get(mapped)
-		if !exists || !option.IsFlag() {
+		if !exists || !(option.IsFlag() || option.Optional) {
 			return ErrOption{
 				ErrKind: ErrKindMistypedCluster,
 				Name:    runtime.name,
@@ -97,16 +74,81 @@ func (runtime *runtimeType) processShortOption(arg string, i *int, inputArgs []s
 				Err:     errors.New(mapped),
 			}
 		}
+		if option.Optional {
+			if err := runtime.setOption(mapped, option.Implicit); err != nil {
+				return err
+			}
+			continue
+		}
 		option.SetFlag()
 	}
 
 	return nil
 }
 
+// resolveAlias returns the name of the option if one of its long aliases is provided.
+func (runtime *runtimeType) resolveAlias(name string) string {
+	if _, exists := runtime.genOptions.Get(name); exists {
+		return name
+	}
+	if mapped, exists := runtime.genOptionAliases[name]; exists {
+		return mapped
+	}
+	return name
+}
+
+// resolveAlt returns the name of the option if one of its single letter alternatives
+// or long aliases is provided.
+func (runtime *runtimeType) resolveAlt(name string) string {
+	if _, exists := runtime.genOptions.Get(name); exists {
+		return name
+	}
+	if mapped, exists := runtime.genOptionAlts[name]; exists {
+		return mapped
+	}
+	return runtime.resolveAlias(name)
+}
+
+// processBareOption handles an option provided without "=<value>".
+// Only options that require a value consume the next argument.
+func (runtime *runtimeType) processBareOption(
+	option *internal.Option,
+	name, arg string,
+	i *int,
+	inputArgs []string,
+) error {
+	if option.IsFlag() {
+		option.SetFlag()
+		return nil
+	}
+	if option.Optional {
+		return runtime.setOption(name, option.Implicit)
+	}
+
+	*i++
+	if *i >= len(inputArgs) {
+		return ErrOption{
+			ErrKind: ErrKindOptionNeedsValue,
+			Name:    runtime.name,
+			Option:  arg,
+			Err:     nil,
+		}
+	}
+	return runtime.setOption(name, inputArgs[*i])
+}
+
 // setOption retrieves the option by name and applies the value.
 func (runtime *runtimeType) setOption(name, value string) error {
 	option, exists := runtime.genOptions.Get(name)
 	if !exists {
+		if _, exists := runtime.genOptionNegs[name]; exists {
+			return ErrOption{
+				ErrKind: ErrKindSettingOption,
+				Name:    runtime.name,
+				Option:  "--" + name,
+				Err:     errors.New("negated flag doesn't take a value"),
+			}
+		}
 		return ErrOption{
 			ErrKind: ErrKindUnknownOption,
 			Name:    runtime.name,
@@ -114,17 +156,44 @@ func (runtime *runtimeType) setOption(name, value string) error {
 			Err:     nil,
 		}
 	}
-	if option.IsFlag() {
-		option.SetFlag()
-		return nil
-	}
 	if err := option.Set(value); err != nil {
+		return runtime.settingError(name, err)
+	}
+	return nil
+}
+
+// settingError wraps the error returned by [internal.Option.Set()] into [ErrOption] of matching kind.
+func (runtime *runtimeType) settingError(name string, err error) ErrOption {
+	var choiceErr internal.ChoiceError
+	if errors.As(err, &choiceErr) {
 		return ErrOption{
-			ErrKind: ErrKindSettingOption,
+			ErrKind: ErrKindInvalidChoice,
 			Name:    runtime.name,
 			Option:  "--" + name,
 			Err:     err,
+			Choices: choiceErr.Choices,
 		}
 	}
-	return nil
+	if errors.As(err, new(internal.ConstraintError)) {
+		return ErrOption{
+			ErrKind: ErrKindConstraint,
+			Name:    runtime.name,
+			Option:  "--" + name,
+			Err:     err,
+		}
+	}
+	if errors.As(err, new(internal.PathError)) {
+		return ErrOption{
+			ErrKind: ErrKindInvalidPath,
+			Name:    runtime.name,
+			Option:  "--" + name,
+			Err:     err,
+		}
+	}
+	return ErrOption{
+		ErrKind: ErrKindSettingOption,
+		Name:    runtime.name,
+		Option:  "--" + name,
+		Err:     err,
+	}
 }
@@ -1,8 +1,11 @@
 package parsex
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"reflect"
+	"strings"
 
 	"github.com/bbfh-dev/parsex/v2/internal"
 	"github.com/iancoleman/strcase"
@@ -10,7 +13,7 @@ import (
 
 func (runtime *runtimeType) preprocess() error {
 	if runtime.data == nil {
-		return nil
+		return runtime.checkArgs()
 	}
 
 	typePtr := reflect.TypeOf(runtime.data)
@@ -31,38 +34,240 @@ func (runtime *runtimeType) preprocess() error {
 		}
 	}
 
-	valueElem := reflect.ValueOf(runtime.data).Elem()
-	numOfFields := typeElem.NumField()
-
 	runtime.genOptions.Clear()
-	runtime.genOptions.Add("help", internal.HelpOption)
+	clear(runtime.genOptionAlts)
+	clear(runtime.genOptionAliases)
+	clear(runtime.genOptionNegs)
+	runtime.genArgs = nil
+	runtime.genRestArg = nil
+	helpOption := internal.HelpOption
+	runtime.genOptions.Add("help", &helpOption)
 	if runtime.version != "" {
-		runtime.genOptions.Add("version", internal.VersionOption)
+		versionOption := internal.VersionOption
+		runtime.genOptions.Add("version", &versionOption)
 	}
 
-	for i := range numOfFields {
+	valueElem := reflect.ValueOf(runtime.data).Elem()
+	if err := runtime.registerFields(typeElem, valueElem, ""); err != nil {
+		return err
+	}
+
+	if runtime.hasHiddenOptions() {
+		helpAllOption := internal.HelpAllOption
+		runtime.genOptions.Add("help-all", &helpAllOption)
+	}
+
+	// Positional arguments are derived from `arg` fields unless set with [runtimeType.SetPosArgs(...)]
+	if len(runtime.posArgs) == 0 || runtime.genPosArgs {
+		runtime.derivePosArgs()
+	}
+	if err := runtime.checkArgs(); err != nil {
+		return err
+	}
+
+	groups := runtime.oneOfGroups()
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		if option.OneOf != "" {
+			option.OneOfGroup, _ = groups.Get(option.OneOf)
+		}
+	})
+
+	return runtime.checkRules()
+}
+
+// registerFields generates options from the fields of the struct.
+//
+// Unexported fields and fields tagged with `parsex:"-"` are skipped.
+// Nested structs are registered recursively with their (kebab-case) field name
+// or `prefix` tag as the prefix of their options. Embedded structs have no prefix by default.
+// Nil pointers to nested structs are allocated.
+func (runtime *runtimeType) registerFields(typeElem reflect.Type, valueElem reflect.Value, prefix string) error {
+	for i := range typeElem.NumField() {
 		fieldType := typeElem.Field(i)
 		fieldValue := valueElem.Field(i)
+		// Embedded structs can be unexported but still have exported fields
+		if fieldType.Tag.Get("parsex") == "-" || (!fieldType.IsExported() && !fieldType.Anonymous) {
+			continue
+		}
+
+		if internal.IsOptionGroup(fieldType.Type) {
+			groupPrefix, hasPrefix := fieldType.Tag.Lookup("prefix")
+			if !hasPrefix && !fieldType.Anonymous {
+				groupPrefix = strcase.ToKebab(fieldType.Name)
+			}
+			if groupPrefix != "" {
+				groupPrefix = prefix + groupPrefix + "-"
+			} else {
+				groupPrefix = prefix
+			}
+			groupType, groupValue := fieldType.Type, fieldValue
+			// Nil pointers are allocated, so that the options can be set
+			if groupType.Kind() == reflect.Pointer {
+				if groupValue.IsNil() {
+					if !groupValue.CanSet() {
+						return ErrProgramData{
+							ErrKind: ErrKindInvalidTag,
+							Name:    runtime.name,
+							Type:    reflect.TypeOf(runtime.data),
+							Field:   fieldType.Name,
+							Err:     errors.New("nil pointer to an unexported struct can't be allocated"),
+						}
+					}
+					groupValue.Set(reflect.New(groupType.Elem()))
+				}
+				groupType, groupValue = groupType.Elem(), groupValue.Elem()
+			}
+			if err := runtime.registerFields(groupType, groupValue, groupPrefix); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !fieldType.IsExported() {
+			continue
+		}
 		if !fieldValue.IsValid() || !fieldValue.CanSet() {
 			log.Printf("WARNING: (Parsex reflection) not possible to modify field %+v", fieldType)
 			continue
 		}
 
-		name := strcase.ToKebab(fieldType.Name)
-		option := internal.Option{
-			Name:    name,
-			Alt:     fieldType.Tag.Get("alt"),
-			Desc:    fieldType.Tag.Get("desc"),
-			Default: fieldType.Tag.Get("default"),
-			Type:    fieldType.Type,
-			Ref:     &fieldValue,
+		name := fieldType.Tag.Get("name")
+		if name == "" {
+			name = strcase.ToKebab(fieldType.Name)
+		}
+		name = prefix + name
+		// Positional arguments aren't split by default
+		index, isArg := fieldType.Tag.Lookup("arg")
+		sep, hasSep := fieldType.Tag.Lookup("sep")
+		if !hasSep && !isArg {
+			sep = ","
+		}
+		implicit, optional := fieldType.Tag.Lookup("optional")
+		option := &internal.Option{
+			Name:       name,
+			Field:      fieldType.Name,
+			Alts:       splitTag(fieldType.Tag.Get("alt")),
+			Aliases:    splitTag(fieldType.Tag.Get("aliases")),
+			Desc:       fieldType.Tag.Get("desc"),
+			Default:    fieldType.Tag.Get("default"),
+			Env:        fieldType.Tag.Get("env"),
+			Sep:        sep,
+			Layout:     fieldType.Tag.Get("layout"),
+			Choices:    splitTag(fieldType.Tag.Get("choices")),
+			Count:      fieldType.Tag.Get("count") == "true",
+			Optional:   optional,
+			Implicit:   implicit,
+			Mode:       fieldType.Tag.Get("mode"),
+			Exists:     fieldType.Tag.Get("exists"),
+			Creatable:  fieldType.Tag.Get("creatable") == "true",
+			Min:        fieldType.Tag.Get("min"),
+			Max:        fieldType.Tag.Get("max"),
+			MinLen:     fieldType.Tag.Get("minlen"),
+			MaxLen:     fieldType.Tag.Get("maxlen"),
+			Pattern:    fieldType.Tag.Get("pattern"),
+			Required:   fieldType.Tag.Get("required") == "true",
+			Conflicts:  splitTag(fieldType.Tag.Get("conflicts")),
+			Requires:   splitTag(fieldType.Tag.Get("requires")),
+			OneOf:      fieldType.Tag.Get("oneof"),
+			Hidden:     fieldType.Tag.Get("hidden") == "true",
+			Deprecated: fieldType.Tag.Get("deprecated"),
+			Group:      fieldType.Tag.Get("group"),
+			Type:       fieldType.Type,
+			Ref:        &fieldValue,
 		}
-		option.Set(option.Default)
-		runtime.genOptions.Add(name, option)
-		if alt := fieldType.Tag.Get("alt"); alt != "" {
-			runtime.genOptionAlts[alt] = name
+		if err := option.Compile(); err != nil {
+			return ErrProgramData{
+				ErrKind: ErrKindInvalidTag,
+				Name:    runtime.name,
+				Type:    reflect.TypeOf(runtime.data),
+				Field:   fieldType.Name,
+				Err:     err,
+			}
 		}
+		if err := option.SetDefault(option.Default); err != nil {
+			return ErrProgramData{
+				ErrKind: ErrKindInvalidTag,
+				Name:    runtime.name,
+				Type:    reflect.TypeOf(runtime.data),
+				Field:   fieldType.Name,
+				Err:     fmt.Errorf("default: %w", err),
+			}
+		}
+		if isArg {
+			if err := runtime.registerArg(index, option); err != nil {
+				return ErrProgramData{
+					ErrKind: ErrKindInvalidTag,
+					Name:    runtime.name,
+					Type:    reflect.TypeOf(runtime.data),
+					Field:   fieldType.Name,
+					Err:     err,
+				}
+			}
+			continue
+		}
+		if option.Env == "" && runtime.envPrefix != "" {
+			option.Env = runtime.envPrefix + "_" + strcase.ToScreamingSnake(name)
+		}
+		if err := runtime.registerNames(option); err != nil {
+			return ErrProgramData{
+				ErrKind: ErrKindInvalidTag,
+				Name:    runtime.name,
+				Type:    reflect.TypeOf(runtime.data),
+				Field:   fieldType.Name,
+				Err:     err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// registerNames registers the option with its name, alternatives and aliases.
+//
+// All of them must be unique, since any of them can be provided as `-<name>`.
+func (runtime *runtimeType) registerNames(option *internal.Option) error {
+	if _, exists := runtime.registeredBy(option.Name); exists {
+		return fmt.Errorf("name %q is already used by another option", option.Name)
+	}
+	runtime.genOptions.Add(option.Name, option)
+	for _, alt := range option.Alts {
+		if owner, exists := runtime.registeredBy(alt); exists && owner != option.Name {
+			return fmt.Errorf("alt %q is already used by --%s", alt, owner)
+		}
+		runtime.genOptionAlts[alt] = option.Name
+	}
+	for _, alias := range option.Aliases {
+		if owner, exists := runtime.registeredBy(alias); exists && owner != option.Name {
+			return fmt.Errorf("alias %q is already used by --%s", alias, owner)
+		}
+		runtime.genOptionAliases[alias] = option.Name
 	}
 
+	if option.IsNegatable() {
+		runtime.genOptionNegs["no-"+option.Name] = option.Name
+		for _, alias := range option.Aliases {
+			runtime.genOptionNegs["no-"+alias] = option.Name
+		}
+	}
 	return nil
 }
+
+// registeredBy returns the name of the option using the name, alternative or alias.
+func (runtime *runtimeType) registeredBy(name string) (string, bool) {
+	if _, exists := runtime.genOptions.Get(name); exists {
+		return name, true
+	}
+	if owner, exists := runtime.genOptionAlts[name]; exists {
+		return owner, true
+	}
+	owner, exists := runtime.genOptionAliases[name]
+	return owner, exists
+}
+
+// Splits a comma-separated tag value, returns nil if the tag is empty
+func splitTag(tag string) []string {
+	if tag == "" {
+		return nil
+	}
+	return strings.Split(tag, ",")
+}
@@ -23,44 +23,144 @@ func (runtime *runtimeType) SafePrintHelp(writer io.Writer) error {
 	if err := runtime.preprocess(); err != nil {
 		return err
 	}
-	runtime.printHelp(writer)
+	runtime.printHelp(writer, false)
 	return nil
 }
 
-func (runtime *runtimeType) printHelp(writer io.Writer) {
+// printHelp prints the help text block.
+// Hidden and deprecated options are only printed if `all` is true.
+//
+// Commands and options are printed in sections titled after their group in registration order,
+// ungrouped ones are printed under "Commands:" and "Options:" and the built-in ones under "Help options:".
+func (runtime *runtimeType) printHelp(writer io.Writer, all bool) {
 	runtime.PrintVersion(writer)
 	fmt.Fprintf(writer, "\n%s\n\nUsage:\n%s%s [options] ", runtime.desc, indent, runtime.name)
 	runtime.printArgs(writer)
 	fmt.Fprintf(writer, "\n")
 
-	if !runtime.branches.IsEmpty() {
-		fmt.Fprint(writer, "\nCommands:\n")
+	if runtime.hasArgDescs() {
+		fmt.Fprint(writer, "\nArguments:\n")
+		for _, arg := range runtime.posArgs {
+			fmt.Fprintf(writer, "%s%s%s\n%s# %s\n", indent, arg, arg.notes(), indent+indent, arg.Desc)
+		}
+	}
 
-		runtime.branches.ForEach(func(name string, branch *runtimeType) {
+	commands := internal.NewOrderedMap[[]string]()
+	commands.Add("Commands", nil)
+	runtime.branches.ForEach(func(name string, _ *runtimeType) {
+		title := "Commands"
+		if group, exists := runtime.branchGroups[name]; exists {
+			title = group
+		}
+		section, _ := commands.Get(title)
+		commands.Add(title, append(section, name))
+	})
+	commands.ForEach(func(title string, names []string) {
+		if len(names) == 0 {
+			return
+		}
+		fmt.Fprintf(writer, "\n%s:\n", title)
+		for _, name := range names {
+			branch, _ := runtime.branches.Get(name)
 			fmt.Fprintf(writer, "%s%s [options] ", indent, name)
 			branch.printArgs(writer)
 			fmt.Fprint(writer, "\n")
+		}
+	})
+
+	options := internal.NewOrderedMap[[]*internal.Option]()
+	options.Add("Help options", nil)
+	options.Add("Options", nil)
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		if option.Deprecated != "" || (option.Hidden && !all) {
+			return
+		}
+		title := "Options"
+		switch {
+		case option.IsBuiltin():
+			title = "Help options"
+		case option.Group != "":
+			title = option.Group
+		}
+		section, _ := options.Get(title)
+		options.Add(title, append(section, option))
+	})
+	options.ForEach(func(title string, section []*internal.Option) {
+		if len(section) == 0 {
+			return
+		}
+		fmt.Fprintf(writer, "\n%s:\n", title)
+		for _, option := range section {
+			printOption(writer, option)
+		}
+	})
+
+	if all && runtime.hasDeprecatedOptions() {
+		fmt.Fprint(writer, "\nDeprecated options:\n")
+
+		runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+			if option.Deprecated != "" {
+				printOption(writer, option)
+			}
 		})
 	}
+}
+
+func printOption(writer io.Writer, option *internal.Option) {
+	fmt.Fprintf(
+		writer,
+		"%s%s\n%s# %s\n",
+		indent,
+		option.String(),
+		indent+indent,
+		option.Desc,
+	)
+}
+
+// hasArgDescs checks whether any positional argument has a description.
+func (runtime *runtimeType) hasArgDescs() bool {
+	for _, arg := range runtime.posArgs {
+		if arg.Desc != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHiddenOptions checks whether any option is only printed by --help-all.
+func (runtime *runtimeType) hasHiddenOptions() bool {
+	hidden := false
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		hidden = hidden || option.Hidden || option.Deprecated != ""
+	})
+	return hidden
+}
 
-	if !runtime.genOptions.IsEmpty() {
-		fmt.Fprint(writer, "\nOptions:\n")
+func (runtime *runtimeType) hasDeprecatedOptions() bool {
+	deprecated := false
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		deprecated = deprecated || option.Deprecated != ""
+	})
+	return deprecated
+}
 
-		runtime.genOptions.ForEach(func(_ string, option internal.Option) {
+// warnDeprecated writes a warning for every deprecated option that was used.
+func (runtime *runtimeType) warnDeprecated() {
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if option.Deprecated != "" && option.IsExplicit() {
 			fmt.Fprintf(
-				writer,
-				"%s%s\n%s# %s\n",
-				indent,
-				option.String(),
-				indent+indent,
-				option.Desc,
+				runtime.warnings,
+				"%s: WARNING: option %q is deprecated: %s\n",
+				runtime.name,
+				"--"+name,
+				option.Deprecated,
 			)
-		})
-	}
+		}
+	})
 }
 
 func (runtime *runtimeType) printArgs(writer io.Writer) {
 	for _, arg := range runtime.posArgs {
-		writer.Write([]byte("<" + arg + "> "))
+		writer.Write([]byte(arg.String() + " "))
 	}
 }
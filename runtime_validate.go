@@ -0,0 +1,168 @@
+package parsex
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+
+	"github.com/bbfh-dev/parsex/v2/internal"
+)
+
+// [Validator] can be implemented by [Program.Data] to validate the options
+// (e.g. cross-field checks) after they are processed, but before [Program.Exec] is called.
+//
+// File options are still nil at this point, since files are only opened once the validation passes.
+type Validator interface {
+	Validate() error
+}
+
+// validateData calls [Validator.Validate()] if [Program.Data] implements it.
+func (runtime *runtimeType) validateData() error {
+	validator, ok := runtime.data.(Validator)
+	if !ok {
+		return nil
+	}
+	if err := validator.Validate(); err != nil {
+		return ErrValidation{
+			ErrKind: ErrKindValidation,
+			Name:    runtime.name,
+			Err:     err,
+		}
+	}
+	return nil
+}
+
+// checkRequired makes sure that every required option got a value.
+func (runtime *runtimeType) checkRequired() error {
+	missing := []string{}
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if option.Required && !option.IsProvided() {
+			missing = append(missing, "--"+name)
+		}
+	})
+	if len(missing) == 0 {
+		return nil
+	}
+	return ErrOption{
+		ErrKind: ErrKindMissingOption,
+		Name:    runtime.name,
+		Option:  missing[0],
+		Err:     nil,
+		Related: missing,
+	}
+}
+
+// oneOfGroups returns names of the options of every `oneof` group.
+func (runtime *runtimeType) oneOfGroups() *internal.OrderedMap[[]string] {
+	groups := internal.NewOrderedMap[[]string]()
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if option.OneOf == "" {
+			return
+		}
+		members, _ := groups.Get(option.OneOf)
+		groups.Add(option.OneOf, append(members, name))
+	})
+	return groups
+}
+
+// checkRules validates the names used by `conflicts`, `requires` and `oneof` rules,
+// so that a typo can't silently disable any of them.
+func (runtime *runtimeType) checkRules() error {
+	var err error
+	runtime.genOptions.ForEach(func(_ string, option *internal.Option) {
+		if err != nil {
+			return
+		}
+		var ruleErr error
+		for _, name := range slices.Concat(option.Conflicts, option.Requires) {
+			if _, exists := runtime.genOptions.Get(name); !exists {
+				ruleErr = fmt.Errorf("unknown option %q", name)
+				break
+			}
+		}
+		if option.OneOf != "" && len(option.OneOfGroup) < 2 {
+			ruleErr = fmt.Errorf("oneof: group %q has no other options", option.OneOf)
+		}
+		if ruleErr != nil {
+			err = ErrProgramData{
+				ErrKind: ErrKindInvalidTag,
+				Name:    runtime.name,
+				Type:    reflect.TypeOf(runtime.data),
+				Field:   option.Field,
+				Err:     ruleErr,
+			}
+		}
+	})
+	return err
+}
+
+// checkGroups validates `conflicts`, `requires` and `oneof` rules of the options.
+func (runtime *runtimeType) checkGroups() error {
+	var err error
+	runtime.genOptions.ForEach(func(name string, option *internal.Option) {
+		if err != nil || !option.IsEnabled() {
+			return
+		}
+
+		conflicting := runtime.filterOptions(option.Conflicts, (*internal.Option).IsEnabled)
+		if len(conflicting) != 0 {
+			err = ErrOption{
+				ErrKind: ErrKindConflictingOptions,
+				Name:    runtime.name,
+				Option:  "--" + name,
+				Err:     nil,
+				Related: conflicting,
+			}
+			return
+		}
+
+		missing := runtime.filterOptions(option.Requires, func(option *internal.Option) bool {
+			return !option.IsProvided()
+		})
+		if len(missing) != 0 {
+			err = ErrOption{
+				ErrKind: ErrKindMissingDependency,
+				Name:    runtime.name,
+				Option:  "--" + name,
+				Err:     nil,
+				Related: missing,
+			}
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	runtime.oneOfGroups().ForEach(func(_ string, members []string) {
+		if err != nil {
+			return
+		}
+		provided := runtime.filterOptions(members, (*internal.Option).IsEnabled)
+		if len(provided) == 1 {
+			return
+		}
+		related := make([]string, len(members))
+		for i, member := range members {
+			related[i] = "--" + member
+		}
+		err = ErrOption{
+			ErrKind: ErrKindOneOfGroup,
+			Name:    runtime.name,
+			Option:  related[0],
+			Err:     nil,
+			Related: related,
+		}
+	})
+	return err
+}
+
+// filterOptions returns flags (e.g. "--name") of the named options matching the predicate.
+func (runtime *runtimeType) filterOptions(names []string, predicate func(*internal.Option) bool) []string {
+	flags := []string{}
+	for _, name := range names {
+		if option, exists := runtime.genOptions.Get(name); exists && predicate(option) {
+			flags = append(flags, "--"+name)
+		}
+	}
+	return flags
+}
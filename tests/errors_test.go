@@ -8,6 +8,18 @@ import (
 	"gotest.tools/assert"
 )
 
+type testDateRange struct {
+	Start int
+	End   int
+}
+
+func (data *testDateRange) Validate() error {
+	if data.End < data.Start {
+		return errors.New("--end must come after --start")
+	}
+	return nil
+}
+
 func TestRuntimeErrorCases(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -33,6 +45,44 @@ func TestRuntimeErrorCases(t *testing.T) {
 			wantErrType: parsex.ErrProgramData{},
 			wantErrKind: parsex.ErrKindPointToStruct,
 		},
+		{
+			name: "InvalidTag",
+			program: func() parsex.Program {
+				var data struct {
+					Name string `min:"1"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "Validation",
+			program: parsex.Program{
+				Data: &testDateRange{},
+				Name: "",
+				Desc: "",
+				Exec: func(_ []string) error { return nil },
+			},
+			programArgs: []string{},
+			args:        []string{"--start", "10", "--end", "5"},
+			wantErrType: parsex.ErrValidation{},
+			wantErrKind: parsex.ErrKindValidation,
+		},
+		{
+			name: "ValidationPasses",
+			program: parsex.Program{
+				Data: &testDateRange{},
+				Name: "",
+				Desc: "",
+				Exec: func(_ []string) error { return nil },
+			},
+			programArgs: []string{},
+			args:        []string{"--start", "5", "--end", "10"},
+			wantErrType: nil,
+		},
 		{
 			name:        "ExecIsNil",
 			program:     parsex.Program{Data: nil, Name: "", Desc: "", Exec: nil},
@@ -67,6 +117,57 @@ func TestRuntimeErrorCases(t *testing.T) {
 			wantErrType: parsex.ErrInput{},
 			wantErrKind: parsex.ErrKindNotEnoughArgs,
 		},
+		{
+			name: "TooManyArgs",
+			program: parsex.Program{
+				Data: nil,
+				Name: "",
+				Desc: "",
+				Exec: func(args []string) error { return nil },
+			},
+			programArgs: []string{"arg1", "arg2?"},
+			args:        []string{"a", "b", "c"},
+			wantErrType: parsex.ErrInput{},
+			wantErrKind: parsex.ErrKindTooManyArgs,
+		},
+		{
+			name: "VariadicArgs",
+			program: parsex.Program{
+				Data: nil,
+				Name: "",
+				Desc: "",
+				Exec: func(args []string) error { return nil },
+			},
+			programArgs: []string{"arg1", "argN..."},
+			args:        []string{"a", "b", "c", "d", "e"},
+			wantErrType: nil,
+		},
+		{
+			name: "VariadicNotLast",
+			program: parsex.Program{
+				Data: nil,
+				Name: "",
+				Desc: "",
+				Exec: func(args []string) error { return nil },
+			},
+			programArgs: []string{"argN...", "arg1"},
+			args:        []string{"a", "b"},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindVariadicNotLast,
+		},
+		{
+			name: "RequiredAfterOptional",
+			program: parsex.Program{
+				Data: nil,
+				Name: "",
+				Desc: "",
+				Exec: func(args []string) error { return nil },
+			},
+			programArgs: []string{"arg1?", "arg2"},
+			args:        []string{"a", "b"},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindRequiredAfterOptional,
+		},
 		{
 			name: "UnknownOption",
 			program: parsex.Program{
@@ -116,6 +217,42 @@ func TestRuntimeErrorCases(t *testing.T) {
 			wantErrType: parsex.ErrOption{},
 			wantErrKind: parsex.ErrKindSettingOption,
 		},
+		{
+			name: "SettingSliceOption",
+			program: func() parsex.Program {
+				var data struct {
+					Numbers []int
+				}
+				return parsex.Program{
+					Data: &data,
+					Name: "",
+					Desc: "",
+					Exec: func(args []string) error { return nil },
+				}
+			}(),
+			programArgs: []string{},
+			args:        []string{"--numbers=1,abc"},
+			wantErrType: parsex.ErrOption{},
+			wantErrKind: parsex.ErrKindSettingOption,
+		},
+		{
+			name: "SettingMapOption",
+			program: func() parsex.Program {
+				var data struct {
+					Label map[string]string
+				}
+				return parsex.Program{
+					Data: &data,
+					Name: "",
+					Desc: "",
+					Exec: func(args []string) error { return nil },
+				}
+			}(),
+			programArgs: []string{},
+			args:        []string{"--label", "env"},
+			wantErrType: parsex.ErrOption{},
+			wantErrKind: parsex.ErrKindSettingOption,
+		},
 		{
 			name: "UnknownCluster",
 			program: func() parsex.Program {
@@ -154,6 +291,162 @@ func TestRuntimeErrorCases(t *testing.T) {
 			wantErrType: parsex.ErrOption{},
 			wantErrKind: parsex.ErrKindMistypedCluster,
 		},
+		{
+			name: "DuplicateName",
+			program: func() parsex.Program {
+				var data struct {
+					DB struct {
+						Host string
+					}
+					DBHost string `name:"db-host"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "DuplicateAlt",
+			program: func() parsex.Program {
+				var data struct {
+					Output string `alt:"o"`
+					Other  string `alt:"o"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "DuplicateAlias",
+			program: func() parsex.Program {
+				var data struct {
+					P     string
+					Other string `aliases:"p"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "DefaultBelowMin",
+			program: func() parsex.Program {
+				var data struct {
+					Level int `default:"5" min:"10"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "DefaultNotChoice",
+			program: func() parsex.Program {
+				var data struct {
+					Format string `choices:"a,b" default:"c"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "DefaultNotParsed",
+			program: func() parsex.Program {
+				var data struct {
+					Retries int `required:"true" default:"abc"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "UnknownConflict",
+			program: func() parsex.Program {
+				var data struct {
+					JSON  bool `name:"json" conflicts:"tabel"`
+					Table bool
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "UnknownRequirement",
+			program: func() parsex.Program {
+				var data struct {
+					Cert string `requires:"kye"`
+					Key  string
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "SingleOneOf",
+			program: func() parsex.Program {
+				var data struct {
+					File string `oneof:"source"`
+					URL  string `oneof:"sorce"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "InvalidArgTag",
+			program: func() parsex.Program {
+				var data struct {
+					Files string `arg:"rest"`
+				}
+				return parsex.Program{Data: &data, Name: "", Desc: "", Exec: nil}
+			}(),
+			programArgs: []string{},
+			args:        []string{},
+			wantErrType: parsex.ErrProgramData{},
+			wantErrKind: parsex.ErrKindInvalidTag,
+		},
+		{
+			name: "InvalidArg",
+			program: func() parsex.Program {
+				var data struct {
+					Port uint16 `arg:"0"`
+				}
+				return parsex.Program{
+					Data: &data,
+					Name: "",
+					Desc: "",
+					Exec: func(args []string) error { return nil },
+				}
+			}(),
+			programArgs: []string{},
+			args:        []string{"70000"},
+			wantErrType: parsex.ErrInput{},
+			wantErrKind: parsex.ErrKindInvalidArg,
+		},
 	}
 
 	for _, testCase := range cases {
@@ -171,6 +464,8 @@ func TestRuntimeErrorCases(t *testing.T) {
 				assert.Equal(t, err.ErrKind, testCase.wantErrKind)
 			case parsex.ErrExecution:
 				assert.Equal(t, err.ErrKind, testCase.wantErrKind)
+			case parsex.ErrValidation:
+				assert.Equal(t, err.ErrKind, testCase.wantErrKind)
 			case parsex.ErrInput:
 				assert.Equal(t, err.ErrKind, testCase.wantErrKind)
 			case parsex.ErrOption:
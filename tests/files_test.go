@@ -0,0 +1,164 @@
+package parsex_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bbfh-dev/parsex/v2"
+	"gotest.tools/assert"
+)
+
+func TestFileOptions(test *testing.T) {
+	dir := test.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	assert.NilError(test, os.WriteFile(input, []byte("hello"), 0o644))
+
+	var data struct {
+		Input  *os.File  `alt:"i" mode:"r"`
+		Source io.Reader `default:"-"`
+		Output io.Writer
+		Log    *os.File `mode:"a"`
+	}
+	var content []byte
+	var opened *os.File
+	program := parsex.Program{
+		Data: &data,
+		Name: "files",
+		Desc: "",
+		Exec: func(args []string) error {
+			var err error
+			content, err = io.ReadAll(data.Input)
+			if err != nil {
+				return err
+			}
+			opened = data.Input
+			_, err = io.WriteString(data.Output, "world")
+			return err
+		},
+	}.Runtime()
+
+	output := filepath.Join(dir, "output.txt")
+	assert.NilError(test, program.Run([]string{"-i", input, "--output", output}))
+	assert.DeepEqual(test, content, []byte("hello"))
+	assert.Equal(test, data.Source, io.Reader(os.Stdin))
+	assert.Assert(test, data.Log == nil)
+
+	written, err := os.ReadFile(output)
+	assert.NilError(test, err)
+	assert.DeepEqual(test, written, []byte("world"))
+
+	// Files are closed once the program is done
+	_, err = opened.Stat()
+	assert.Assert(test, errors.Is(err, os.ErrClosed))
+
+	assert.NilError(test, program.Run([]string{"-i", "-", "--output", "-"}))
+	assert.Equal(test, data.Input, os.Stdin)
+	assert.Equal(test, data.Output, io.Writer(os.Stdout))
+
+	err = program.Run([]string{"-i", filepath.Join(dir, "missing.txt"), "--output", "-"})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--input, -i <file>\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--source <file> (default: -)\n")))
+}
+
+func TestFilesOpenedLast(test *testing.T) {
+	dir := test.TempDir()
+	keep := filepath.Join(dir, "keep.txt")
+	assert.NilError(test, os.WriteFile(keep, []byte("keep"), 0o644))
+
+	var data struct {
+		Out   io.Writer
+		Level int `max:"3"`
+	}
+	var written io.Writer
+	program := parsex.Program{
+		Data: &data,
+		Name: "files",
+		Desc: "",
+		Exec: func(args []string) error { written = data.Out; return nil },
+	}.Runtime()
+
+	for _, args := range [][]string{
+		{"--out", keep, "--bogus"},
+		{"--out", keep, "--level", "5"},
+		{"--out", keep, "--help"},
+	} {
+		program.Run(args)
+		content, err := os.ReadFile(keep)
+		assert.NilError(test, err)
+		assert.DeepEqual(test, content, []byte("keep"))
+	}
+	assert.Assert(test, written == nil)
+
+	assert.NilError(test, program.Run([]string{"--out", keep}))
+	assert.Assert(test, written != nil)
+	content, err := os.ReadFile(keep)
+	assert.NilError(test, err)
+	assert.Equal(test, len(content), 0)
+}
+
+func TestFileArgs(test *testing.T) {
+	var data struct {
+		Input  io.Reader `arg:"0"`
+		Output io.Writer `arg:"1" default:"-"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "files",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"-"}))
+	assert.Equal(test, data.Input, io.Reader(os.Stdin))
+	assert.Equal(test, data.Output, io.Writer(os.Stdout))
+}
+
+func TestPathOptions(test *testing.T) {
+	dir := test.TempDir()
+	config := filepath.Join(dir, "config.yaml")
+	assert.NilError(test, os.WriteFile(config, []byte{}, 0o644))
+
+	var data struct {
+		Config string   `exists:"file"`
+		Cache  string   `exists:"dir"`
+		Output string   `creatable:"true"`
+		Extra  []string `exists:"file"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "paths",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{
+		"--config", config,
+		"--cache", dir,
+		"--output", filepath.Join(dir, "new.txt"),
+		"--extra", config,
+	}))
+
+	for _, args := range [][]string{
+		{"--config", dir},
+		{"--config", filepath.Join(dir, "missing.yaml")},
+		{"--cache", config},
+		{"--output", dir},
+		{"--output", filepath.Join(dir, "missing", "new.txt")},
+		{"--extra", config + "," + dir},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindInvalidPath)
+	}
+}
@@ -0,0 +1,1175 @@
+package parsex_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"math/big"
+	"net"
+	"net/netip"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bbfh-dev/parsex/v2"
+	"gotest.tools/assert"
+)
+
+func TestSliceOptions(test *testing.T) {
+	var data struct {
+		Include []string  `alt:"I" desc:"Include paths" default:"/usr/include"`
+		Numbers []int     `desc:"Some numbers"`
+		Ratios  []float64 `desc:"Some ratios" sep:";"`
+		Raw     []string  `desc:"Not split" sep:""`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "slices",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.DeepEqual(test, data.Include, []string{"/usr/include"})
+
+	assert.NilError(test, program.Run([]string{
+		"--include", "a",
+		"--include=b,c",
+		"--numbers=1,2", "--numbers", "3",
+		"--ratios", "0.5;1.5",
+		"--raw", "a,b",
+	}))
+	assert.DeepEqual(test, data.Include, []string{"a", "b", "c"})
+	assert.DeepEqual(test, data.Numbers, []int{1, 2, 3})
+	assert.DeepEqual(test, data.Ratios, []float64{0.5, 1.5})
+	assert.DeepEqual(test, data.Raw, []string{"a,b"})
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(
+		buffer.Bytes(),
+		[]byte("--include, -I <string>... (default: /usr/include)"),
+	))
+}
+
+func TestMapOptions(test *testing.T) {
+	var data struct {
+		Label map[string]string `alt:"l" desc:"Labels"`
+		Limit map[string]int    `desc:"Limits" default:"cpu=2"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "maps",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.DeepEqual(test, data.Limit, map[string]int{"cpu": 2})
+
+	assert.NilError(test, program.Run([]string{
+		"--label", "env=prod",
+		"--label=team=core,tier=1",
+		"--limit", "mem=512",
+	}))
+	assert.DeepEqual(test, data.Label, map[string]string{"env": "prod", "team": "core", "tier": "1"})
+	assert.DeepEqual(test, data.Limit, map[string]int{"mem": 512})
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--label, -l <string=string>...")))
+}
+
+func TestNumericOptions(test *testing.T) {
+	var data struct {
+		Small  int8
+		Medium int64
+		Count  uint16
+		Mask   uint8
+		Ratio  float32
+		Huge   *big.Int
+		Exact  *big.Float
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "numbers",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{
+		"--small=-128",
+		"--medium", "1_000_000",
+		"--count", "0x_FF",
+		"--mask", "0b1010",
+		"--ratio", "0.25",
+		"--huge", "0o777777777777777777777777",
+		"--exact", "1.5e100",
+	}))
+	assert.DeepEqual(test, data.Small, int8(-128))
+	assert.DeepEqual(test, data.Medium, int64(1_000_000))
+	assert.DeepEqual(test, data.Count, uint16(0xFF))
+	assert.DeepEqual(test, data.Mask, uint8(0b1010))
+	assert.DeepEqual(test, data.Ratio, float32(0.25))
+	assert.DeepEqual(test, data.Huge.Text(8), "777777777777777777777777")
+	assert.DeepEqual(test, data.Exact.Text('e', 1), "1.5e+100")
+
+	for _, args := range [][]string{
+		{"--small", "128"},
+		{"--count", "-1"},
+		{"--mask", "256"},
+		{"--huge", "12abc"},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+	}
+}
+
+func TestTimeOptions(test *testing.T) {
+	var data struct {
+		Timeout time.Duration `desc:"Request timeout" default:"5m"`
+		Retries []time.Duration
+		Since   time.Time `layout:"2006-01-02"`
+		Until   time.Time
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "times",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.DeepEqual(test, data.Timeout, 5*time.Minute)
+
+	assert.NilError(test, program.Run([]string{
+		"--timeout", "30s",
+		"--retries=1s,1m",
+		"--since", "2024-02-29",
+		"--until=2024-03-01T12:00:00Z",
+	}))
+	assert.DeepEqual(test, data.Timeout, 30*time.Second)
+	assert.DeepEqual(test, data.Retries, []time.Duration{time.Second, time.Minute})
+	assert.Assert(test, data.Since.Equal(time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)))
+	assert.Assert(test, data.Until.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	err := program.Run([]string{"--since", "29.02.2024"})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--timeout <duration> (default: 5m)")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--retries <duration>...")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--since <time>")))
+}
+
+type testLevel int
+
+func (level *testLevel) Set(value string) error {
+	for i, name := range []string{"debug", "info", "warn"} {
+		if value == name {
+			*level = testLevel(i)
+			return nil
+		}
+	}
+	return errors.New("unknown level")
+}
+
+func (level *testLevel) String() string {
+	return []string{"debug", "info", "warn"}[*level]
+}
+
+func (level *testLevel) Type() string {
+	return "level"
+}
+
+type testRegion struct {
+	Zone string
+}
+
+func (region *testRegion) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		return errors.New("empty region")
+	}
+	region.Zone = strings.ToUpper(string(text))
+	return nil
+}
+
+func TestCustomOptions(test *testing.T) {
+	var data struct {
+		Level   testLevel `desc:"Log level" default:"info"`
+		Region  testRegion
+		Regions []testRegion
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "custom",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Equal(test, data.Level, testLevel(1))
+
+	assert.NilError(test, program.Run([]string{
+		"--level", "warn",
+		"--region", "eu-west",
+		"--regions=us,ap",
+	}))
+	assert.Equal(test, data.Level, testLevel(2))
+	assert.DeepEqual(test, data.Region, testRegion{Zone: "EU-WEST"})
+	assert.DeepEqual(test, data.Regions, []testRegion{{Zone: "US"}, {Zone: "AP"}})
+
+	for _, args := range [][]string{
+		{"--level", "trace"},
+		{"--region="},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--level <level> (default: info)")))
+}
+
+type testTags []string
+
+func (tags *testTags) Set(value string) error {
+	*tags = append(*tags, value)
+	return nil
+}
+
+func (tags *testTags) String() string {
+	return strings.Join(*tags, ",")
+}
+
+func (tags *testTags) Type() string {
+	return "tag"
+}
+
+func TestAccumulatingValueOptions(test *testing.T) {
+	var data struct {
+		Tags  testTags `default:"x"`
+		Notes testTags
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "accumulating",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--tags", "y", "--notes", "a", "--notes", "b"}))
+	assert.DeepEqual(test, data.Tags, testTags{"y"})
+	assert.DeepEqual(test, data.Notes, testTags{"a", "b"})
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.NilError(test, program.Run([]string{"--tags", "y", "--tags", "z"}))
+	assert.DeepEqual(test, data.Tags, testTags{"y", "z"})
+	assert.Equal(test, len(data.Notes), 0)
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.DeepEqual(test, data.Tags, testTags{"x"})
+}
+
+func TestPointerOptions(test *testing.T) {
+	var data struct {
+		Count   *int
+		Name    *string
+		Force   *bool          `alt:"f"`
+		Timeout *time.Duration `default:"1m"`
+		Level   *testLevel
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "pointers",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Assert(test, data.Count == nil)
+	assert.Assert(test, data.Name == nil)
+	assert.Assert(test, data.Force == nil)
+	assert.Assert(test, data.Level == nil)
+	assert.Equal(test, *data.Timeout, time.Minute)
+
+	assert.NilError(test, program.Run([]string{
+		"--count", "0",
+		"--name=",
+		"-f",
+		"--timeout", "0s",
+		"--level", "debug",
+	}))
+	assert.Equal(test, *data.Count, 0)
+	assert.Equal(test, *data.Name, "")
+	assert.Equal(test, *data.Force, true)
+	assert.Equal(test, *data.Timeout, time.Duration(0))
+	assert.Equal(test, *data.Level, testLevel(0))
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Assert(test, data.Count == nil)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--count <int>\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--[no-]force, -f\n")))
+}
+
+func TestChoiceOptions(test *testing.T) {
+	var data struct {
+		Format  string   `alt:"f" choices:"json,yaml,table" default:"table"`
+		Columns []string `choices:"name,size,date"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "choices",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--format", "json", "--columns=name,date"}))
+	assert.Equal(test, data.Format, "json")
+	assert.DeepEqual(test, data.Columns, []string{"name", "date"})
+
+	for _, args := range [][]string{
+		{"--format", "xml"},
+		{"--columns", "name,owner"},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindInvalidChoice)
+		assert.Assert(test, len(optionErr.Choices) == 3)
+	}
+
+	options, err := program.Options()
+	assert.NilError(test, err)
+	assert.DeepEqual(test, options[1].Choices, []string{"json", "yaml", "table"})
+	assert.Equal(test, options[1].Name, "format")
+	assert.DeepEqual(test, options[1].Alts, []string{"f"})
+	assert.Equal(test, options[1].Usage, "--format, -f <json|yaml|table> (default: table)")
+	assert.Equal(test, options[2].Repeatable, true)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--format, -f <json|yaml|table> (default: table)")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--columns <name|size|date>...")))
+}
+
+func TestCounterOptions(test *testing.T) {
+	var data struct {
+		Verbose int  `alt:"v" count:"true"`
+		Debug   bool `alt:"d"`
+		Level   int8 `alt:"l" count:"true" default:"1"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "counters",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"-vvv"}))
+	assert.Equal(test, data.Verbose, 3)
+	assert.Equal(test, data.Level, int8(1))
+
+	assert.NilError(test, program.Run([]string{"-vdv", "--verbose", "-ll"}))
+	assert.Equal(test, data.Verbose, 3)
+	assert.Equal(test, data.Debug, true)
+	assert.Equal(test, data.Level, int8(2))
+
+	assert.NilError(test, program.Run([]string{"--verbose=5", "-v"}))
+	assert.Equal(test, data.Verbose, 6)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--verbose, -v (repeatable)\n")))
+}
+
+func TestBooleanOptions(test *testing.T) {
+	var data struct {
+		Color   bool  `default:"true"`
+		Force   bool  `alt:"f"`
+		Confirm *bool `alt:"y"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "booleans",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Equal(test, data.Color, true)
+
+	assert.NilError(test, program.Run([]string{"--no-color", "--force=yes", "-confirm=0"}))
+	assert.Equal(test, data.Color, false)
+	assert.Equal(test, data.Force, true)
+	assert.Equal(test, *data.Confirm, false)
+
+	assert.NilError(test, program.Run([]string{"--color=false", "-f", "--force=FALSE", "-no-confirm"}))
+	assert.Equal(test, data.Color, false)
+	assert.Equal(test, data.Force, false)
+	assert.Equal(test, *data.Confirm, false)
+
+	for _, args := range [][]string{
+		{"--force=maybe"},
+		{"--force=t"},
+		{"--no-force=true"},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--[no-]color (default: true)\n")))
+}
+
+func TestOptionalValueOptions(test *testing.T) {
+	var data struct {
+		Color  string `alt:"c" optional:"auto" default:"never"`
+		Debug  bool   `alt:"d"`
+		Number int    `alt:"N"`
+	}
+	var args []string
+	program := parsex.Program{
+		Data: &data,
+		Name: "optional",
+		Desc: "",
+		Exec: func(a []string) error { args = a; return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Equal(test, data.Color, "never")
+
+	assert.NilError(test, program.Run([]string{"--color", "always"}))
+	assert.Equal(test, data.Color, "auto")
+	assert.DeepEqual(test, args, []string{"always"})
+
+	assert.NilError(test, program.Run([]string{"--color=always", "-N", "15"}))
+	assert.Equal(test, data.Color, "always")
+	assert.Equal(test, data.Number, 15)
+
+	assert.NilError(test, program.Run([]string{"-c=always", "-dc"}))
+	assert.Equal(test, data.Color, "auto")
+	assert.Equal(test, data.Debug, true)
+
+	assert.NilError(test, program.Run([]string{"-c", "file"}))
+	assert.Equal(test, data.Color, "auto")
+	assert.DeepEqual(test, args, []string{"file"})
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--color[=<string>], -c (default: never)")))
+}
+
+func TestByteSizeOptions(test *testing.T) {
+	var data struct {
+		Cache parsex.ByteSize `default:"64M"`
+		Limit parsex.ByteSize
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "sizes",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Equal(test, data.Cache, 64*parsex.Mebibyte)
+
+	cases := map[string]parsex.ByteSize{
+		"512":    512,
+		"512K":   512 * parsex.Kibibyte,
+		"10MB":   10_000_000,
+		"10MiB":  10 * parsex.Mebibyte,
+		"1.5G":   1536 * parsex.Mebibyte,
+		"2 tb":   2e12,
+		"15EiB":  15 * parsex.Exbibyte,
+		"0.5KiB": 512,
+	}
+	for input, want := range cases {
+		assert.NilError(test, program.Run([]string{"--limit", input}))
+		assert.Equal(test, data.Limit, want, input)
+	}
+
+	for _, input := range []string{"16EiB", "100000PB", "1.5B", "10XB", "M", "-1K"} {
+		err := program.Run([]string{"--limit", input})
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", input, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--cache <size> (default: 64MiB)")))
+}
+
+func TestStdlibOptions(test *testing.T) {
+	var data struct {
+		Addr     netip.Addr
+		Subnet   netip.Prefix
+		Listen   netip.AddrPort `default:"127.0.0.1:8080"`
+		Peers    []net.IP
+		Endpoint *url.URL
+		Pattern  *regexp.Regexp
+		Mode     os.FileMode `default:"0644"`
+		DirMode  os.FileMode `default:"0o755"`
+		Zone     *time.Location
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "stdlib",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{
+		"--addr", "::1",
+		"--subnet", "10.0.0.0/8",
+		"--peers=10.0.0.1,10.0.0.2",
+		"--endpoint", "https://example.com/api?x=1",
+		"--pattern", "^v[0-9]+$",
+		"--zone", "UTC",
+	}))
+	assert.Equal(test, data.Addr, netip.MustParseAddr("::1"))
+	assert.Equal(test, data.Subnet, netip.MustParsePrefix("10.0.0.0/8"))
+	assert.Equal(test, data.Listen, netip.MustParseAddrPort("127.0.0.1:8080"))
+	assert.Equal(test, len(data.Peers), 2)
+	assert.Assert(test, data.Peers[1].Equal(net.ParseIP("10.0.0.2")))
+	assert.Equal(test, data.Endpoint.Host, "example.com")
+	assert.Assert(test, data.Pattern.MatchString("v12"))
+	assert.Equal(test, data.Mode, os.FileMode(0o644))
+	assert.Equal(test, data.DirMode, os.FileMode(0o755))
+	assert.Equal(test, data.Zone, time.UTC)
+
+	assert.NilError(test, program.Run([]string{"--mode", "0O600", "--dir-mode", "700"}))
+	assert.Equal(test, data.Mode, os.FileMode(0o600))
+	assert.Equal(test, data.DirMode, os.FileMode(0o700))
+
+	for _, args := range [][]string{
+		{"--addr", "localhost"},
+		{"--peers", "10.0.0.256"},
+		{"--pattern", "[a-"},
+		{"--mode", "0999"},
+		{"--zone", "Mars/Olympus"},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindSettingOption)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	for _, usage := range []string{
+		"--addr <addr>\n",
+		"--subnet <prefix>\n",
+		"--listen <addr:port> (default: 127.0.0.1:8080)\n",
+		"--peers <ip>...\n",
+		"--endpoint <url>\n",
+		"--pattern <regexp>\n",
+		"--mode <mode> (default: 0644)\n",
+		"--zone <location>\n",
+	} {
+		assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte(usage)), usage)
+	}
+}
+
+func TestConstraintOptions(test *testing.T) {
+	var data struct {
+		Port    int             `min:"1" max:"65535" default:"8080"`
+		Ratio   float64         `min:"0" max:"1"`
+		Timeout time.Duration   `min:"1s"`
+		Cache   parsex.ByteSize `max:"1GiB"`
+		Name    string          `minlen:"2" maxlen:"8" pattern:"[a-z]+"`
+		Tags    []string        `maxlen:"3"`
+		Retries *uint           `max:"5"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "constraints",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{
+		"--port", "65535",
+		"--ratio", "0.5",
+		"--timeout", "1s",
+		"--cache", "512MiB",
+		"--name", "parsex",
+		"--tags=a,bc,def",
+		"--retries", "5",
+	}))
+
+	for _, args := range [][]string{
+		{"--port", "0"},
+		{"--port", "65536"},
+		{"--ratio", "1.01"},
+		{"--timeout", "500ms"},
+		{"--cache", "2G"},
+		{"--name", "a"},
+		{"--name", "parsexlib"},
+		{"--name", "Parsex"},
+		{"--tags", "a,long"},
+		{"--retries", "6"},
+	} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindConstraint, args)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	for _, usage := range []string{
+		"--port <int> (default: 8080, min: 1, max: 65535)\n",
+		"--name <string> (minlen: 2, maxlen: 8, pattern: [a-z]+)\n",
+	} {
+		assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte(usage)), usage)
+	}
+}
+
+func TestRequiredOptions(test *testing.T) {
+	var data struct {
+		Token  string   `required:"true"`
+		Region string   `required:"true" default:"eu"`
+		Hosts  []string `required:"true"`
+		Force  bool     `required:"true"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "required",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	err := program.Run([]string{"--force"})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindMissingOption)
+	assert.DeepEqual(test, optionErr.Related, []string{"--token", "--hosts"})
+
+	assert.NilError(test, program.Run([]string{"--token=", "--hosts", "a", "--no-force"}))
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--region <string> (required, default: eu)\n")))
+}
+
+func TestOptionGroups(test *testing.T) {
+	var data struct {
+		Json  bool   `conflicts:"table"`
+		Table bool   `conflicts:"json"`
+		Cert  string `requires:"key"`
+		Key   string
+		File  string `oneof:"source"`
+		Url   string `oneof:"source"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "groups",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--json", "--file", "a.txt"}))
+	assert.NilError(test, program.Run([]string{"--json", "--no-table", "--file", "a.txt"}))
+	assert.NilError(test, program.Run([]string{"--table=false", "--json", "--file", "a.txt"}))
+	assert.NilError(test, program.Run([]string{"--cert", "a.pem", "--key", "a.key", "--url", "x"}))
+
+	cases := []struct {
+		args []string
+		kind parsex.ErrKind
+	}{
+		{[]string{"--json", "--table", "--file", "a"}, parsex.ErrKindConflictingOptions},
+		{[]string{"--cert", "a.pem", "--file", "a"}, parsex.ErrKindMissingDependency},
+		{[]string{}, parsex.ErrKindOneOfGroup},
+		{[]string{"--file", "a", "--url", "b"}, parsex.ErrKindOneOfGroup},
+	}
+	for _, testCase := range cases {
+		err := program.Run(testCase.args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", testCase.args, err)
+		assert.Equal(test, optionErr.ErrKind, testCase.kind, testCase.args)
+	}
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	for _, usage := range []string{
+		"--cert <string> (requires: --key)\n",
+		"--[no-]json (conflicts with: --table)\n",
+		"--url <string> (one of: --file/--url)\n",
+	} {
+		assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte(usage)), usage)
+	}
+}
+
+type testLogging struct {
+	Verbose bool   `alt:"v"`
+	Format  string `default:"text"`
+}
+
+func TestNestedOptions(test *testing.T) {
+	var data struct {
+		testLogging
+		DB struct {
+			Host string `default:"localhost"`
+			Port int    `default:"5432"`
+		}
+		Cache struct {
+			Size parsex.ByteSize
+			Peer struct {
+				Addr string
+			}
+		} `prefix:"redis"`
+		Shared struct {
+			Token string
+		} `prefix:""`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "nested",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{
+		"-v",
+		"--format", "json",
+		"--db-port", "6543",
+		"--redis-size", "1M",
+		"--redis-peer-addr", "10.0.0.1",
+		"--token", "secret",
+	}))
+	assert.Equal(test, data.Verbose, true)
+	assert.Equal(test, data.Format, "json")
+	assert.Equal(test, data.DB.Host, "localhost")
+	assert.Equal(test, data.DB.Port, 6543)
+	assert.Equal(test, data.Cache.Size, parsex.Mebibyte)
+	assert.Equal(test, data.Cache.Peer.Addr, "10.0.0.1")
+	assert.Equal(test, data.Shared.Token, "secret")
+
+	options, err := program.Options()
+	assert.NilError(test, err)
+	names := []string{}
+	for _, option := range options {
+		names = append(names, option.Name)
+	}
+	assert.DeepEqual(test, names, []string{
+		"help", "verbose", "format", "db-host", "db-port", "redis-size", "redis-peer-addr", "token",
+	})
+}
+
+func TestOneOfFlags(test *testing.T) {
+	var data struct {
+		JSON  bool `name:"json" oneof:"format"`
+		Table bool `oneof:"format"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "flags",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--no-json", "--table"}))
+	assert.NilError(test, program.Run([]string{"--json", "--table=false"}))
+
+	for _, args := range [][]string{{"--no-json"}, {"--json=false", "--no-table"}} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindOneOfGroup)
+	}
+}
+
+type testCommon struct {
+	Host string `default:"localhost"`
+}
+
+func TestNestedPointerOptions(test *testing.T) {
+	var data struct {
+		*testCommon
+		DB *struct {
+			Port int `default:"5432"`
+		}
+	}
+	data.testCommon = &testCommon{}
+	program := parsex.Program{
+		Data: &data,
+		Name: "pointers",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--host", "example.com"}))
+	assert.Equal(test, data.Host, "example.com")
+	assert.Assert(test, data.DB != nil)
+	assert.Equal(test, data.DB.Port, 5432)
+
+	data.testCommon = nil
+	err := program.Run([]string{})
+	dataErr, ok := err.(parsex.ErrProgramData)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, dataErr.ErrKind, parsex.ErrKindInvalidTag)
+}
+
+func TestOptionNames(test *testing.T) {
+	var data struct {
+		IPv6Addr  string `name:"ipv6-addr"`
+		HTTPSPort int    `name:"https-port" alt:"p"`
+		Internal  string `parsex:"-"`
+		Skipped   struct {
+			Value string
+		} `parsex:"-"`
+		DB struct {
+			URL string `name:"url"`
+		}
+		hidden string
+		priv   struct {
+			X string
+		}
+	}
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	program := parsex.Program{
+		Data: &data,
+		Name: "names",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"--ipv6-addr", "::1", "-p", "443", "--db-url", "pg://"}))
+	assert.Equal(test, data.IPv6Addr, "::1")
+	assert.Equal(test, data.HTTPSPort, 443)
+	assert.Equal(test, data.DB.URL, "pg://")
+	assert.Equal(test, data.hidden, "")
+
+	assert.Equal(test, logs.String(), "")
+
+	for _, args := range [][]string{{"--internal", "x"}, {"--skipped-value", "x"}, {"--hidden", "x"}, {"--priv-x", "x"}} {
+		err := program.Run(args)
+		optionErr, ok := err.(parsex.ErrOption)
+		assert.Assert(test, ok, "%v: unexpected error %v", args, err)
+		assert.Equal(test, optionErr.ErrKind, parsex.ErrKindUnknownOption)
+	}
+}
+
+func TestHiddenOptions(test *testing.T) {
+	var data struct {
+		Output string `desc:"Output file"`
+		Trace  bool   `hidden:"true"`
+		Out    string `deprecated:"use --output instead"`
+	}
+	var warnings bytes.Buffer
+	program := parsex.Program{
+		Data: &data,
+		Name: "hidden",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime().SetWarningWriter(&warnings)
+
+	assert.NilError(test, program.Run([]string{"--trace", "--output", "a.txt"}))
+	assert.Equal(test, data.Trace, true)
+	assert.Equal(test, warnings.String(), "")
+
+	assert.NilError(test, program.Run([]string{"--out", "b.txt"}))
+	assert.Equal(test, data.Out, "b.txt")
+	assert.Equal(test, warnings.String(), "hidden: WARNING: option \"--out\" is deprecated: use --output instead\n")
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--output <string>\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--help-all\n")))
+	assert.Assert(test, !bytes.Contains(buffer.Bytes(), []byte("--[no-]trace")))
+	assert.Assert(test, !bytes.Contains(buffer.Bytes(), []byte("--out <string>")))
+}
+
+func TestOptionAliases(test *testing.T) {
+	var data struct {
+		Output string `alt:"o,O" aliases:"out,output-file" desc:"Output file"`
+		Color  bool   `alt:"c" aliases:"colour" default:"true"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "aliases",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime()
+
+	for _, args := range [][]string{
+		{"--output", "a.txt"},
+		{"--output=a.txt"},
+		{"-o", "a.txt"},
+		{"-O=a.txt"},
+		{"--out", "a.txt"},
+		{"--output-file=a.txt"},
+		{"-out", "a.txt"},
+	} {
+		data.Output = ""
+		assert.NilError(test, program.Run(args), "%v", args)
+		assert.Equal(test, data.Output, "a.txt", "%v", args)
+	}
+
+	assert.NilError(test, program.Run([]string{"--no-colour"}))
+	assert.Equal(test, data.Color, false)
+	assert.NilError(test, program.Run([]string{"--colour=false", "-c"}))
+	assert.Equal(test, data.Color, true)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--output, -o, -O, --out, --output-file <string>\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--[no-]color, -c, --colour (default: true)\n")))
+}
+
+func TestHelpSections(test *testing.T) {
+	var data struct {
+		Verbose bool   `desc:"Print more"`
+		Host    string `group:"Network" desc:"Remote host"`
+		Port    int    `group:"Network" desc:"Remote port"`
+		Secret  string `group:"Auth" hidden:"true"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "sections",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime().
+		RegisterCommand(parsex.Program{Name: "init"}.Runtime()).
+		RegisterCommandGroup(
+			"Remote commands",
+			parsex.Program{Name: "push"}.Runtime(),
+			parsex.Program{Name: "pull"}.Runtime(),
+		)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte(`
+Commands:
+    init [options] 
+
+Remote commands:
+    push [options] 
+    pull [options] 
+
+Help options:
+    --help
+        # Print this help message
+    --help-all
+        # Print this help message including hidden and deprecated options
+
+Options:
+    --[no-]verbose
+        # Print more
+
+Network:
+    --host <string>
+        # Remote host
+    --port <int>
+        # Remote port
+`)), buffer.String())
+	assert.Assert(test, !bytes.Contains(buffer.Bytes(), []byte("Auth:")))
+}
+
+func TestPositionalArgs(test *testing.T) {
+	var data struct {
+		Verbose bool          `alt:"v"`
+		Count   int           `arg:"0"`
+		Timeout time.Duration `arg:"1" default:"5s"`
+		Level   testLevel     `arg:"2" default:"info"`
+		Files   []string      `arg:"rest"`
+	}
+	var execArgs []string
+	program := parsex.Program{
+		Data: &data,
+		Name: "args",
+		Desc: "",
+		Exec: func(args []string) error { execArgs = args; return nil },
+	}.Runtime()
+
+	assert.NilError(test, program.Run([]string{"3", "-v", "1m", "debug", "a.txt", "b,c.txt"}))
+	assert.Equal(test, data.Verbose, true)
+	assert.Equal(test, data.Count, 3)
+	assert.Equal(test, data.Timeout, time.Minute)
+	assert.Equal(test, data.Level, testLevel(0))
+	assert.DeepEqual(test, data.Files, []string{"a.txt", "b,c.txt"})
+	assert.DeepEqual(test, execArgs, []string{"3", "1m", "debug", "a.txt", "b,c.txt"})
+
+	assert.NilError(test, program.Run([]string{"7"}))
+	assert.Equal(test, data.Count, 7)
+	assert.Equal(test, data.Timeout, 5*time.Second)
+	assert.Equal(test, len(data.Files), 0)
+
+	err := program.Run([]string{"seven"})
+	inputErr, ok := err.(parsex.ErrInput)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, inputErr.ErrKind, parsex.ErrKindInvalidArg)
+	assert.Equal(test, inputErr.Arg, "count")
+
+	err = program.Run([]string{})
+	inputErr, ok = err.(parsex.ErrInput)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, inputErr.ErrKind, parsex.ErrKindNotEnoughArgs)
+
+	err = program.Run([]string{"--count", "1"})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindUnknownOption)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("args [options] <count> <timeout?> <level?> <files?...> \n")))
+}
+
+func TestArgSpec(test *testing.T) {
+	program := parsex.Program{
+		Data: nil,
+		Name: "copy",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime().SetArgs(
+		parsex.Arg{Name: "dest", Desc: "Destination directory"},
+		parsex.Arg{Name: "files", Desc: "Files to copy", Variadic: true, Min: 1, Max: 3},
+	)
+
+	assert.NilError(test, program.Run([]string{"out", "a"}))
+	assert.NilError(test, program.Run([]string{"out", "a", "b", "c"}))
+
+	err := program.Run([]string{"out"})
+	inputErr, ok := err.(parsex.ErrInput)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, inputErr.ErrKind, parsex.ErrKindNotEnoughArgs)
+	assert.Equal(test, inputErr.RequiredLen, 2)
+
+	err = program.Run([]string{"out", "a", "b", "c", "d"})
+	inputErr, ok = err.(parsex.ErrInput)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, inputErr.ErrKind, parsex.ErrKindTooManyArgs)
+	assert.Equal(test, inputErr.MaxLen, 4)
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte(`
+Usage:
+    copy [options] <dest> <files...> 
+
+Arguments:
+    <dest>
+        # Destination directory
+    <files...> (min: 1, max: 3)
+        # Files to copy
+`)), buffer.String())
+
+	for _, arg := range []parsex.Arg{
+		{Name: "files", Variadic: true, Min: 3, Max: 1},
+		{Name: "files", Optional: true, Variadic: true, Min: 2},
+	} {
+		err = program.SetArgs(arg).Run([]string{"a"})
+		dataErr, ok := err.(parsex.ErrProgramData)
+		assert.Assert(test, ok, "%+v: unexpected error %v", arg, err)
+		assert.Equal(test, dataErr.ErrKind, parsex.ErrKindInvalidArgRange)
+	}
+}
+
+func TestEnvOptions(test *testing.T) {
+	var data struct {
+		Port    int      `env:"TEST_PORT" default:"8080" desc:"Port to listen on"`
+		Debug   bool     `desc:"Enable debug mode"`
+		Tags    []string `desc:"Tags"`
+		DBHost  string   `name:"db-host" default:"localhost"`
+		Timeout time.Duration
+		Verbose int `alt:"v" count:"true"`
+	}
+	program := parsex.Program{
+		Data: &data,
+		Name: "env",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime().SetEnvPrefix("APP")
+
+	test.Setenv("TEST_PORT", "9090")
+	test.Setenv("APP_DEBUG", "yes")
+	test.Setenv("APP_TAGS", "a,b")
+	test.Setenv("APP_DB_HOST", "")
+	test.Setenv("APP_VERBOSE", "5")
+
+	assert.NilError(test, program.Run([]string{}))
+	assert.Equal(test, data.Port, 9090)
+	assert.Equal(test, data.Debug, true)
+	assert.DeepEqual(test, data.Tags, []string{"a", "b"})
+	assert.Equal(test, data.DBHost, "localhost")
+	assert.Equal(test, data.Verbose, 5)
+
+	assert.NilError(test, program.Run([]string{"--port", "7070", "--no-debug", "--tags", "c", "-vv"}))
+	assert.Equal(test, data.Port, 7070)
+	assert.Equal(test, data.Verbose, 2)
+	assert.Equal(test, data.Debug, false)
+	assert.DeepEqual(test, data.Tags, []string{"c"})
+
+	test.Setenv("APP_TIMEOUT", "soon")
+	assert.NilError(test, program.Run([]string{"--version"}))
+	assert.NilError(test, program.Run([]string{"--timeout", "1s"}))
+	assert.Equal(test, data.Timeout, time.Second)
+
+	err := program.Run([]string{})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindInvalidEnv)
+	assert.Equal(test, optionErr.Env, "APP_TIMEOUT")
+	assert.Equal(test, optionErr.Option, "--timeout")
+
+	var buffer bytes.Buffer
+	assert.NilError(test, program.SafePrintHelp(&buffer))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--port <int> (default: 8080, env: TEST_PORT)\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--[no-]debug (env: APP_DEBUG)\n")))
+	assert.Assert(test, bytes.Contains(buffer.Bytes(), []byte("--db-host <string> (default: localhost, env: APP_DB_HOST)\n")))
+	assert.Assert(test, !bytes.Contains(buffer.Bytes(), []byte("APP_HELP")))
+}
+
+func TestEnvOptionsWithCommand(test *testing.T) {
+	var data struct {
+		Verbose bool `env:"TEST_VERBOSE"`
+		Port    int  `env:"TEST_PORT"`
+	}
+	var ran bool
+	program := parsex.Program{
+		Data: &data,
+		Name: "tool",
+		Desc: "",
+		Exec: func(args []string) error { return nil },
+	}.Runtime().RegisterCommand(parsex.Program{
+		Name: "build",
+		Exec: func(args []string) error { ran = true; return nil },
+	}.Runtime())
+
+	test.Setenv("TEST_VERBOSE", "true")
+	assert.NilError(test, program.Run([]string{"build"}))
+	assert.Equal(test, ran, true)
+	assert.Equal(test, data.Verbose, true)
+
+	test.Setenv("TEST_PORT", "abc")
+	err := program.Run([]string{"build"})
+	optionErr, ok := err.(parsex.ErrOption)
+	assert.Assert(test, ok, "unexpected error %v", err)
+	assert.Equal(test, optionErr.ErrKind, parsex.ErrKindInvalidEnv)
+	assert.NilError(test, program.Run([]string{"build", "--version"}))
+}
@@ -50,14 +50,16 @@ Usage:
 Commands:
     build [options] <filename?> 
 
-Options:
+Help options:
     --help
         # Print this help message
     --version
         # Print the program version
-    --verbose, -v
+
+Options:
+    --[no-]verbose, -v
         # Print verbose debug information
-    --debug, -d
+    --[no-]debug, -d
         # Run the program in DEBUG mode
     --input <string>
         # Some input file
@@ -0,0 +1,12 @@
+package parsex
+
+import "github.com/bbfh-dev/parsex/v2/internal"
+
+// [Value] is the interface for custom option types.
+//
+// If the pointer to a field of [Program.Data] implements it, [Value.Set()] is called
+// with every provided value, [Value.Type()] is used as the `<type>` placeholder
+// and [Value.String()] displays the default value in the --help menu.
+//
+// Types implementing [encoding.TextUnmarshaler] are supported as well.
+type Value = internal.Value